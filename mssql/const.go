@@ -7,10 +7,14 @@ const (
   usernameProp             = "username"
   objectIdProp             = "object_id"
   passwordProp             = "password"
+  passwordWoProp           = "password_wo"
+  passwordWoVersionProp    = "password_wo_version"
   sidStrProp               = "sid"
   clientIdProp             = "client_id"
   authenticationTypeProp   = "authentication_type"
   defaultSchemaProp        = "default_schema"
   defaultSchemaPropDefault = "dbo"
   rolesProp                = "roles"
+  adoptExistingProp        = "adopt_existing"
+  previewSqlProp           = "preview_sql"
 )
@@ -0,0 +1,120 @@
+package mssql
+
+import (
+  "context"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+  "github.com/betr-io/terraform-provider-mssql/mssql/model"
+)
+
+func dataSourceLogin() *schema.Resource {
+  return &schema.Resource{
+    ReadContext: dataSourceLoginRead,
+    Schema: map[string]*schema.Schema{
+      serverProp: {
+        Type:     schema.TypeList,
+        MaxItems: 1,
+        Required: true,
+        Elem: &schema.Resource{
+          Schema: getServerSchema(serverProp),
+        },
+      },
+      loginNameProp: {
+        Type:     schema.TypeString,
+        Required: true,
+      },
+      principalIdProp: {
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      loginTypeProp: {
+        Type:     schema.TypeString,
+        Computed: true,
+      },
+      sidStrProp: {
+        Type:     schema.TypeString,
+        Computed: true,
+      },
+      passwordHashProp: {
+        Type:      schema.TypeString,
+        Computed:  true,
+        Sensitive: true,
+      },
+      defaultDatabaseProp: {
+        Type:     schema.TypeString,
+        Computed: true,
+      },
+      defaultLanguageProp: {
+        Type:     schema.TypeString,
+        Computed: true,
+      },
+      checkPolicyProp: {
+        Type:     schema.TypeBool,
+        Computed: true,
+      },
+      checkExpirationProp: {
+        Type:     schema.TypeBool,
+        Computed: true,
+      },
+      enabledProp: {
+        Type:     schema.TypeBool,
+        Computed: true,
+      },
+    },
+    Timeouts: &schema.ResourceTimeout{
+      Read: defaultTimeout,
+    },
+  }
+}
+
+func dataSourceLoginRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := meta.(model.Provider).DataSourceLogger("login", "read")
+  logger.Debug().Msgf("Read %s", data.Get(loginNameProp))
+
+  loginName := data.Get(loginNameProp).(string)
+
+  connector, err := getLoginConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  login, err := connector.GetLogin(ctx, loginName)
+  if err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to read login [%s]", loginName)
+  }
+  if login == nil {
+    return diag.Errorf("login [%s] not found", loginName)
+  }
+
+  if err = data.Set(principalIdProp, login.PrincipalID); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(loginTypeProp, login.Type); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(sidStrProp, login.SIDStr); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(passwordHashProp, login.PasswordHash); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(defaultDatabaseProp, login.DefaultDatabase); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(defaultLanguageProp, login.DefaultLanguage); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(checkPolicyProp, login.CheckPolicy); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(checkExpirationProp, login.CheckExpiration); err != nil {
+    return diag.FromErr(err)
+  }
+  if err = data.Set(enabledProp, login.Enabled); err != nil {
+    return diag.FromErr(err)
+  }
+
+  data.SetId(getLoginID(data))
+
+  return nil
+}
@@ -0,0 +1,45 @@
+package mssql
+
+import (
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+  "testing"
+)
+
+func TestAccDataSourceLogin_Local_Clone(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "source", false, map[string]interface{}{"login_name": "login_source", "password": "valueIsH8kd$¡"}) + `
+          data "mssql_login" "source" {
+            server {
+              host = "localhost"
+              login {}
+            }
+            login_name = mssql_login.source.login_name
+          }
+
+          resource "mssql_login" "clone" {
+            server {
+              host = "localhost"
+              login {}
+            }
+            login_name    = "login_clone"
+            password_hash = data.mssql_login.source.password_hash
+          }`,
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttrPair("data.mssql_login.source", "principal_id", "mssql_login.source", "principal_id"),
+          resource.TestCheckResourceAttrPair("data.mssql_login.source", "sid", "mssql_login.source", "sid"),
+          resource.TestCheckResourceAttr("data.mssql_login.source", "type", "sql"),
+          resource.TestCheckResourceAttrSet("data.mssql_login.source", "password_hash"),
+          resource.TestCheckResourceAttrPair("mssql_login.clone", "password_hash", "data.mssql_login.source", "password_hash"),
+          testAccCheckLoginExists("mssql_login.clone"),
+        ),
+      },
+    },
+  })
+}
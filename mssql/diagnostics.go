@@ -0,0 +1,115 @@
+package mssql
+
+import (
+  "fmt"
+  "github.com/hashicorp/go-cty/cty"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+  mssql "github.com/microsoft/go-mssqldb"
+  "github.com/pkg/errors"
+  "strings"
+)
+
+type sqlErrorHint struct {
+  hint string
+  // The attribute the error is about, when it is the same for every resource
+  attribute string
+}
+
+// Remediation hints for common SQL Server errors
+var sqlErrorHints = map[int32]sqlErrorHint{
+  229:   {hint: "The server login of the provider lacks permission for this operation. Grant it the required permission, or manage the resource with a more privileged login."},
+  4062:  {hint: "The default database of the login cannot be opened. Check that default_database exists and that the login has access to it.", attribute: defaultDatabaseProp},
+  15007: {hint: "The login does not exist. Create the login before referencing it, e.g. with an mssql_login resource."},
+  15023: {hint: "The user already exists in the database. Import it with `terraform import`, set adopt_existing to take it over, or choose another name."},
+  15025: {hint: "The server principal already exists. Import it with `terraform import`, set adopt_existing to take it over, or choose another name."},
+  15115: {hint: "The password does not meet the password policy of the server. Choose a shorter password.", attribute: passwordProp},
+  15116: {hint: "The password does not meet the password policy of the server. Choose a longer password.", attribute: passwordProp},
+  15118: {hint: "The password does not meet the password policy of the server. Choose a longer or more complex password.", attribute: passwordProp},
+  15128: {hint: "The login must change its password before CHECK_POLICY can be turned off. Set a new password, or keep check_policy enabled."},
+  15151: {hint: "The principal cannot be found, or the server login of the provider lacks permission to it. Check that the referenced login, user or role exists."},
+  15247: {hint: "The server login of the provider lacks permission for this operation. Grant it the required permission, or manage the resource with a more privileged login."},
+  15434: {hint: "The login has active sessions. Close the sessions of the login before dropping it."},
+}
+
+// Returns the attribute the SQL Server error is about, or else attribute, which names the principal of the resource. A
+// role or credential that cannot be found is one of the roles, server roles or credentials of the principal.
+func sqlErrorAttribute(e mssql.Error, attribute string) string {
+  if hint := sqlErrorHints[e.SQLErrorNumber()]; hint.attribute != "" {
+    return hint.attribute
+  }
+  if e.SQLErrorNumber() == 15151 {
+    message := strings.ToLower(e.SQLErrorMessage())
+    switch {
+    case attribute == usernameProp && strings.Contains(message, "role"):
+      return rolesProp
+    case attribute == loginNameProp && strings.Contains(message, "role"):
+      return serverRolesProp
+    case attribute == loginNameProp && strings.Contains(message, "credential"):
+      return credentialsProp
+    }
+  }
+  return attribute
+}
+
+// Reports whether err carries a SQL Server error with one of numbers
+func isSqlError(err error, numbers ...int32) bool {
+  var sqlErr mssql.Error
+  if !errors.As(err, &sqlErr) {
+    return false
+  }
+  sqlErrs := sqlErr.All
+  if len(sqlErrs) == 0 {
+    sqlErrs = []mssql.Error{sqlErr}
+  }
+  for _, e := range sqlErrs {
+    for _, number := range numbers {
+      if e.SQLErrorNumber() == number {
+        return true
+      }
+    }
+  }
+  return false
+}
+
+// Converts an error from a connector to diagnostics. Each SQL Server error carried by err becomes a separate diagnostic
+// with its number, severity, state, procedure and line, a remediation hint if known, and the path of the attribute it is
+// about, which is attribute unless the error is known to be about another.
+func sqlDiagnostics(err error, attribute string, format string, args ...interface{}) diag.Diagnostics {
+  summary := fmt.Sprintf(format, args...)
+
+  var sqlErr mssql.Error
+  if !errors.As(err, &sqlErr) {
+    return diag.FromErr(errors.Wrap(err, summary))
+  }
+
+  sqlErrs := sqlErr.All
+  if len(sqlErrs) == 0 {
+    sqlErrs = []mssql.Error{sqlErr}
+  }
+
+  var diags diag.Diagnostics
+  for _, e := range sqlErrs {
+    // Severity 10 and below are informational messages, e.g. from PRINT
+    if e.SQLErrorClass() <= 10 {
+      continue
+    }
+    detail := fmt.Sprintf("SQL Server error %d, severity %d, state %d", e.SQLErrorNumber(), e.SQLErrorClass(), e.SQLErrorState())
+    if e.SQLErrorProcName() != "" {
+      detail += fmt.Sprintf(", procedure %s", e.SQLErrorProcName())
+    }
+    detail += fmt.Sprintf(", line %d", e.SQLErrorLineNo())
+    if hint, ok := sqlErrorHints[e.SQLErrorNumber()]; ok {
+      detail += "\n\n" + hint.hint
+    }
+    diags = append(diags, diag.Diagnostic{
+      Severity:      diag.Error,
+      Summary:       fmt.Sprintf("%s: %s", summary, strings.TrimSpace(e.SQLErrorMessage())),
+      Detail:        detail,
+      AttributePath: cty.GetAttrPath(sqlErrorAttribute(e, attribute)),
+    })
+  }
+  if len(diags) == 0 {
+    return diag.FromErr(errors.Wrap(err, summary))
+  }
+  return diags
+}
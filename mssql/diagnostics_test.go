@@ -0,0 +1,106 @@
+package mssql
+
+import (
+  "github.com/hashicorp/go-cty/cty"
+  mssql "github.com/microsoft/go-mssqldb"
+  "github.com/pkg/errors"
+  "strings"
+  "testing"
+)
+
+func TestSqlDiagnostics(t *testing.T) {
+  err := mssql.Error{
+    Number:  15434,
+    State:   1,
+    Class:   16,
+    Message: "Could not drop login 'login_basic' as the user is currently logged in.",
+    LineNo:  1,
+    All: []mssql.Error{
+      {Number: 0, Class: 0, Message: "Killing session 51 for login login_basic"},
+      {Number: 15434, State: 1, Class: 16, Message: "Could not drop login 'login_basic' as the user is currently logged in.", LineNo: 1},
+    },
+  }
+
+  diags := sqlDiagnostics(errors.WithStack(err), loginNameProp, "unable to delete login [%s]", "login_basic")
+  if len(diags) != 1 {
+    t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+  }
+  if expected := "unable to delete login [login_basic]: Could not drop login 'login_basic' as the user is currently logged in."; diags[0].Summary != expected {
+    t.Errorf("expected summary %q, got %q", expected, diags[0].Summary)
+  }
+  if !strings.HasPrefix(diags[0].Detail, "SQL Server error 15434, severity 16, state 1, line 1") {
+    t.Errorf("unexpected detail %q", diags[0].Detail)
+  }
+  if !strings.Contains(diags[0].Detail, sqlErrorHints[15434].hint) {
+    t.Errorf("expected detail to contain hint, got %q", diags[0].Detail)
+  }
+  if !diags[0].AttributePath.Equals(cty.GetAttrPath(loginNameProp)) {
+    t.Errorf("expected attribute path %s, got %#v", loginNameProp, diags[0].AttributePath)
+  }
+}
+
+func TestSqlDiagnostics_Attribute(t *testing.T) {
+  tests := []struct {
+    number    int32
+    message   string
+    attribute string
+    expected  string
+  }{
+    {15118, "Password validation failed. The password does not meet the operating system policy requirements because it is not complex enough.", loginNameProp, passwordProp},
+    {15116, "Password validation failed. The password does not meet the operating system policy requirements because it is too short.", usernameProp, passwordProp},
+    {4062, "Cannot open user default database. Using master database instead.", loginNameProp, defaultDatabaseProp},
+    {15151, "Cannot alter the role 'db_nonexistent', because it does not exist or you do not have permission.", usernameProp, rolesProp},
+    {15151, "Cannot alter the server role 'nonexistent', because it does not exist or you do not have permission.", loginNameProp, serverRolesProp},
+    {15151, "Cannot find the credential 'nonexistent', because it does not exist or you do not have permission.", loginNameProp, credentialsProp},
+    {15151, "Cannot find the login 'nonexistent', because it does not exist or you do not have permission.", usernameProp, usernameProp},
+    {15434, "Could not drop login 'login_basic' as the user is currently logged in.", loginNameProp, loginNameProp},
+  }
+  for _, test := range tests {
+    err := mssql.Error{Number: test.number, Class: 16, Message: test.message}
+    diags := sqlDiagnostics(err, test.attribute, "unable to update")
+    if len(diags) != 1 {
+      t.Fatalf("expected 1 diagnostic for error %d, got %d", test.number, len(diags))
+    }
+    if !diags[0].AttributePath.Equals(cty.GetAttrPath(test.expected)) {
+      t.Errorf("expected attribute path %s for error %d on %s, got %#v", test.expected, test.number, test.attribute, diags[0].AttributePath)
+    }
+  }
+}
+
+func TestIsSqlError(t *testing.T) {
+  err := mssql.Error{
+    Number:  15025,
+    Class:   16,
+    Message: "The server principal 'login_basic' already exists.",
+    All: []mssql.Error{
+      {Number: 15025, Class: 16, Message: "The server principal 'login_basic' already exists."},
+      {Number: 15151, Class: 16, Message: "Cannot find the login 'login_basic', because it does not exist or you do not have permission."},
+    },
+  }
+
+  if !isSqlError(errors.WithStack(err), 15025) {
+    t.Errorf("expected error 15025 to be found")
+  }
+  if !isSqlError(err, 15023, 15151) {
+    t.Errorf("expected error 15151 to be found")
+  }
+  if isSqlError(err, 15023) {
+    t.Errorf("expected error 15023 not to be found")
+  }
+  if isSqlError(errors.New("db connection failed"), 15025) {
+    t.Errorf("expected no SQL Server error")
+  }
+}
+
+func TestSqlDiagnostics_NotSqlError(t *testing.T) {
+  diags := sqlDiagnostics(errors.New("db connection failed"), usernameProp, "unable to read user [%s].[%s]", "master", "user")
+  if len(diags) != 1 {
+    t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+  }
+  if expected := "unable to read user [master].[user]: db connection failed"; diags[0].Summary != expected {
+    t.Errorf("expected summary %q, got %q", expected, diags[0].Summary)
+  }
+  if diags[0].AttributePath != nil {
+    t.Errorf("expected no attribute path, got %#v", diags[0].AttributePath)
+  }
+}
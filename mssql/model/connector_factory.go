@@ -4,4 +4,5 @@ import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 type ConnectorFactory interface {
   GetConnector(prefix string, data *schema.ResourceData) (interface{}, error)
+  GetDiffConnector(prefix string, diff *schema.ResourceDiff) (interface{}, error)
 }
@@ -0,0 +1,8 @@
+package model
+
+type Credential struct {
+  CredentialID int64
+  Name         string
+  Identity     string
+  Secret       string
+}
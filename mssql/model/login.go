@@ -1,9 +1,30 @@
 package model
 
 type Login struct {
-  PrincipalID     int64
-  LoginName       string
-  SIDStr          string
-  DefaultDatabase string
-  DefaultLanguage string
+  PrincipalID         int64
+  LoginName           string
+  Password            string
+  PasswordHash        string
+  Type                string
+  ObjectId            string
+  CertificateName     string
+  AsymmetricKeyName   string
+  SIDStr              string
+  DefaultDatabase     string
+  DefaultLanguage     string
+  CheckPolicy         bool
+  CheckExpiration     bool
+  MustChangePassword  bool
+  Enabled             bool
+  DenyConnectSql      bool
+  // Server roles the login is a member of; nil leaves the memberships of the login as they are
+  ServerRoles         []string
+  // Credentials mapped to the login; nil leaves the credentials of the login as they are
+  Credentials         []string
+  Unlock              bool
+  IsLocked            bool
+  IsExpired           bool
+  BadPasswordCount    int
+  PasswordLastSetTime string
+  DaysUntilExpiration int
 }
@@ -7,6 +7,8 @@ import (
 
 type Provider interface {
   GetConnector(prefix string, data *schema.ResourceData) (interface{}, error)
+  GetDiffConnector(prefix string, diff *schema.ResourceDiff) (interface{}, error)
+  DryRun() bool
   ResourceLogger(resource, function string) zerolog.Logger
   DataSourceLogger(datasource, function string) zerolog.Logger
 }
@@ -1,14 +1,19 @@
 package model
 
 type User struct {
-  PrincipalID     int64
-  Username        string
-  ObjectId        string
-  LoginName       string
-  Password        string
-  SIDStr          string
-  AuthType        string
-  DefaultSchema   string
-  DefaultLanguage string
-  Roles           []string
+  PrincipalID       int64
+  Username          string
+  ObjectId          string
+  LoginName         string
+  CertificateName   string
+  AsymmetricKeyName string
+  Password          string
+  OldPassword       string
+  SIDStr            string
+  AuthType          string
+  PrincipalType     string
+  DefaultSchema     string
+  DefaultLanguage   string
+  Roles             []string
+  EffectiveRoles    []string
 }
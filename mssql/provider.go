@@ -17,6 +17,7 @@ import (
 type mssqlProvider struct {
   factory model.ConnectorFactory
   logger  *zerolog.Logger
+  dryRun  bool
 }
 
 const (
@@ -42,12 +43,22 @@ func Provider(factory model.ConnectorFactory) *schema.Provider {
         Optional:    true,
         Default:     false,
       },
+      "dry_run": {
+        Type:        schema.TypeBool,
+        Description: "Compute the T-SQL of planned changes in the preview_sql attribute of each resource instead of executing it",
+        Optional:    true,
+        Default:     false,
+      },
     },
     ResourcesMap: map[string]*schema.Resource{
-      "mssql_login": resourceLogin(),
-      "mssql_user":  resourceUser(),
+      "mssql_credential":    resourceCredential(),
+      "mssql_login":         resourceLogin(),
+      "mssql_login_replica": resourceLoginReplica(),
+      "mssql_user":          resourceUser(),
+    },
+    DataSourcesMap: map[string]*schema.Resource{
+      "mssql_login": dataSourceLogin(),
     },
-    DataSourcesMap: map[string]*schema.Resource{},
     ConfigureContextFunc: func(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
       return providerConfigure(ctx, data, factory)
     },
@@ -60,13 +71,21 @@ func providerConfigure(ctx context.Context, data *schema.ResourceData, factory m
 
   logger.Info().Msg("Created provider")
 
-  return mssqlProvider{factory: factory, logger: logger}, nil
+  return mssqlProvider{factory: factory, logger: logger, dryRun: data.Get("dry_run").(bool)}, nil
 }
 
 func (p mssqlProvider) GetConnector(prefix string, data *schema.ResourceData) (interface{}, error) {
   return p.factory.GetConnector(prefix, data)
 }
 
+func (p mssqlProvider) GetDiffConnector(prefix string, diff *schema.ResourceDiff) (interface{}, error) {
+  return p.factory.GetDiffConnector(prefix, diff)
+}
+
+func (p mssqlProvider) DryRun() bool {
+  return p.dryRun
+}
+
 func (p mssqlProvider) ResourceLogger(resource, function string) zerolog.Logger {
   return p.logger.With().Str("resource", resource).Str("func", function).Logger()
 }
@@ -5,8 +5,10 @@ import (
   "context"
   sql2 "database/sql"
   "fmt"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
   "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
   "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+  "net/url"
   "os"
   "strconv"
   "github.com/betr-io/terraform-provider-mssql/mssql/model"
@@ -16,6 +18,11 @@ import (
   "time"
 )
 
+const testAccDryRunProvider = `provider "mssql" {
+  dry_run = true
+}
+`
+
 var runLocalAccTests bool
 var testAccProvider *schema.Provider
 var testAccProviders map[string]func() (*schema.Provider, error)
@@ -53,6 +60,16 @@ func testAccPreCheck(t *testing.T) {
   }
 }
 
+// Checks that the resource is not in the state, as after a create in dry run mode
+func testAccCheckNotInState(resource string) resource.TestCheckFunc {
+  return func(state *terraform.State) error {
+    if _, ok := state.RootModule().Resources[resource]; ok {
+      return fmt.Errorf("expected %s not to be in the state", resource)
+    }
+    return nil
+  }
+}
+
 type Check struct {
   name, op string
   expected interface{}
@@ -60,9 +77,11 @@ type Check struct {
 
 type TestConnector interface {
   GetLogin(name string) (*model.Login, error)
+  GetCredential(name string) (*model.Credential, error)
   GetUser(database, name string) (*model.User, error)
   GetSystemUser() (string, error)
   GetCurrentUser(database string) (string, string, error)
+  Exec(database, command string) error
 }
 
 type testConnector struct {
@@ -154,6 +173,10 @@ func (t testConnector) GetLogin(name string) (*model.Login, error) {
   return t.c.(LoginConnector).GetLogin(context.Background(), name)
 }
 
+func (t testConnector) GetCredential(name string) (*model.Credential, error) {
+  return t.c.(CredentialConnector).GetCredential(context.Background(), name)
+}
+
 func (t testConnector) GetUser(database, name string) (*model.User, error) {
   return t.c.(UserConnector).GetUser(context.Background(), database, name)
 }
@@ -178,6 +201,38 @@ func (t testConnector) GetCurrentUser(database string) (string, string, error) {
   return current, system, err
 }
 
+func (t testConnector) Exec(database, command string) error {
+  t.c.(*sql.Connector).Database = database
+  return t.c.(*sql.Connector).ExecContext(context.Background(), command)
+}
+
+// Executes command against the local SQL Server before a test step, and cleanup once the test is done
+func testAccLocalExec(t *testing.T, database, command, cleanup string) func() {
+  return func() {
+    prefix := serverProp + ".0."
+    connector, err := getTestConnector(map[string]string{
+      prefix + "host":             "localhost",
+      prefix + "port":             DefaultPort,
+      prefix + "login.0.username": os.Getenv("MSSQL_USERNAME"),
+      prefix + "login.0.password": os.Getenv("MSSQL_PASSWORD"),
+    })
+    if err != nil {
+      t.Fatalf("%s", err)
+    }
+    if err = connector.Exec(database, command); err != nil {
+      t.Fatalf("%s", err)
+    }
+    if cleanup == "" {
+      return
+    }
+    t.Cleanup(func() {
+      if err := connector.Exec(database, cleanup); err != nil {
+        t.Errorf("%s", err)
+      }
+    })
+  }
+}
+
 func templateToString(name, text string, data interface{}) (string, error) {
   t, err := template.New(name).Parse(text)
   if err != nil {
@@ -190,6 +245,8 @@ func templateToString(name, text string, data interface{}) (string, error) {
   return doc.String(), nil
 }
 
+// Returns the ID that imports resource, which holds the login name, or the database and user name, rather than the
+// principal id of the resource ID
 func testAccImportStateId(resource string, azure bool) func(state *terraform.State) (string, error) {
   return func(state *terraform.State) (string, error) {
     rs, ok := state.RootModule().Resources[resource]
@@ -199,6 +256,17 @@ func testAccImportStateId(resource string, azure bool) func(state *terraform.Sta
     if rs.Primary.ID == "" {
       return "", fmt.Errorf("no record ID is set")
     }
-    return rs.Primary.ID + "?azure=" + strconv.FormatBool(azure), nil
+    attributes := rs.Primary.Attributes
+    path := "/" + attributes[loginNameProp]
+    if database, ok := attributes[databaseProp]; ok {
+      path = "/" + database + "/" + attributes[usernameProp]
+    }
+    id := url.URL{
+      Scheme:   "sqlserver",
+      Host:     attributes[serverProp+".0.host"] + ":" + attributes[serverProp+".0.port"],
+      Path:     path,
+      RawQuery: "azure=" + strconv.FormatBool(azure),
+    }
+    return id.String(), nil
   }
 }
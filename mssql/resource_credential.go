@@ -0,0 +1,293 @@
+package mssql
+
+import (
+  "context"
+  "fmt"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+  "github.com/pkg/errors"
+  "strings"
+  "github.com/betr-io/terraform-provider-mssql/mssql/model"
+  "github.com/betr-io/terraform-provider-mssql/sql"
+)
+
+const credentialNameProp = "name"
+const identityProp = "identity"
+const secretWoProp = "secret_wo"
+const secretWoVersionProp = "secret_wo_version"
+const credentialIdProp = "credential_id"
+
+func resourceCredential() *schema.Resource {
+  return &schema.Resource{
+    CreateContext: resourceCredentialCreate,
+    ReadContext:   resourceCredentialRead,
+    UpdateContext: resourceCredentialUpdate,
+    DeleteContext: resourceCredentialDelete,
+    CustomizeDiff: resourceCredentialCustomizeDiff,
+    Importer: &schema.ResourceImporter{
+      StateContext: resourceCredentialImport,
+    },
+    Schema: map[string]*schema.Schema{
+      serverProp: {
+        Type:     schema.TypeList,
+        MaxItems: 1,
+        Required: true,
+        Elem: &schema.Resource{
+          Schema: getServerSchema(serverProp),
+        },
+      },
+      credentialNameProp: {
+        Type:     schema.TypeString,
+        Required: true,
+        ForceNew: true,
+      },
+      identityProp: {
+        Type:     schema.TypeString,
+        Required: true,
+      },
+      secretWoProp: {
+        Type:      schema.TypeString,
+        Optional:  true,
+        Sensitive: true,
+        WriteOnly: true,
+      },
+      secretWoVersionProp: {
+        Type:     schema.TypeInt,
+        Optional: true,
+      },
+      credentialIdProp: {
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      previewSqlProp: previewSqlSchema(),
+    },
+    Timeouts: &schema.ResourceTimeout{
+      Default: defaultTimeout,
+      Read: defaultTimeout,
+    },
+  }
+}
+
+type CredentialConnector interface {
+  CreateCredential(ctx context.Context, credential *model.Credential) error
+  GetCredential(ctx context.Context, name string) (*model.Credential, error)
+  UpdateCredential(ctx context.Context, credential *model.Credential) error
+  DeleteCredential(ctx context.Context, name string) error
+}
+
+func resourceCredentialCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "credential", "create")
+  logger.Debug().Msgf("Create %s", getCredentialID(data))
+
+  name := data.Get(credentialNameProp).(string)
+
+  credential := &model.Credential{
+    Name:     name,
+    Identity: data.Get(identityProp).(string),
+    Secret:   getWriteOnly(data, secretWoProp),
+  }
+  if isDryRun(meta) {
+    return dryRunDiagnostics(fmt.Sprintf("create credential [%s]", name), sql.CreateCredentialStatements(credential))
+  }
+
+  connector, err := getCredentialConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  if err = connector.CreateCredential(ctx, credential); err != nil {
+    return sqlDiagnostics(err, credentialNameProp, "unable to create credential [%s]", name)
+  }
+
+  data.SetId(getCredentialID(data))
+
+  logger.Info().Msgf("created credential [%s]", name)
+
+  return resourceCredentialRead(ctx, data, meta)
+}
+
+func resourceCredentialRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "credential", "read")
+  logger.Debug().Msgf("Read %s", getCredentialID(data))
+
+  name := data.Get(credentialNameProp).(string)
+
+  connector, err := getCredentialConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  credential, err := connector.GetCredential(ctx, name)
+  if err != nil {
+    return sqlDiagnostics(err, credentialNameProp, "unable to read credential [%s]", name)
+  }
+  if credential == nil {
+    logger.Info().Msgf("No credential found for [%s]", name)
+    data.SetId("")
+    return nil
+  }
+
+  if err = setCredential(data, credential); err != nil {
+    return diag.FromErr(err)
+  }
+
+  return nil
+}
+
+func resourceCredentialUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "credential", "update")
+  logger.Debug().Msgf("Update %s", data.Id())
+
+  name := data.Get(credentialNameProp).(string)
+
+  credential := &model.Credential{
+    Name:     name,
+    Identity: data.Get(identityProp).(string),
+    Secret:   getWriteOnly(data, secretWoProp),
+  }
+  if isDryRun(meta) {
+    return append(dryRunDiagnostics(fmt.Sprintf("update credential [%s]", name), sql.UpdateCredentialStatements(credential)), keepPriorState(data, resourceCredential().Schema)...)
+  }
+
+  connector, err := getCredentialConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  if err = connector.UpdateCredential(ctx, credential); err != nil {
+    return sqlDiagnostics(err, identityProp, "unable to update credential [%s]", name)
+  }
+
+  logger.Info().Msgf("updated credential [%s]", name)
+
+  return resourceCredentialRead(ctx, data, meta)
+}
+
+func resourceCredentialDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "credential", "delete")
+  logger.Debug().Msgf("Delete %s", data.Id())
+
+  name := data.Get(credentialNameProp).(string)
+
+  if isDryRun(meta) {
+    return dryRunDeleteDiagnostics(fmt.Sprintf("delete credential [%s]", name), sql.DeleteCredentialStatements(name))
+  }
+
+  connector, err := getCredentialConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  if err = connector.DeleteCredential(ctx, name); err != nil {
+    return sqlDiagnostics(err, credentialNameProp, "unable to delete credential [%s]", name)
+  }
+
+  logger.Info().Msgf("deleted credential [%s]", name)
+
+  data.SetId("")
+
+  return nil
+}
+
+func resourceCredentialCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+  if config := diff.GetRawConfig(); !config.IsNull() && config.GetAttr(secretWoProp).IsNull() && !config.GetAttr(secretWoVersionProp).IsNull() {
+    return errors.Errorf("%s requires %s", secretWoVersionProp, secretWoProp)
+  }
+  // ALTER CREDENTIAL sets the secret along with the identity, so a credential with a secret gets its secret again
+  if diff.Id() != "" && diff.HasChange(identityProp) && isConfigured(diff, secretWoVersionProp) && !diff.HasChange(secretWoVersionProp) {
+    return errors.Errorf("changing %s requires a new %s, as the secret is set again along with the identity", identityProp, secretWoVersionProp)
+  }
+
+  if !isDryRun(meta) {
+    return nil
+  }
+
+  for _, key := range []string{credentialNameProp, identityProp} {
+    if !diff.NewValueKnown(key) {
+      return diff.SetNewComputed(previewSqlProp)
+    }
+  }
+
+  oldName, _ := diff.GetChange(credentialNameProp)
+  credential := &model.Credential{
+    Name:     diff.Get(credentialNameProp).(string),
+    Identity: diff.Get(identityProp).(string),
+    Secret:   getWriteOnly(diff, secretWoProp),
+  }
+  var statements []string
+  if diff.Id() == "" {
+    statements = sql.CreateCredentialStatements(credential)
+  } else if diff.HasChange(credentialNameProp) {
+    statements = append(sql.DeleteCredentialStatements(oldName.(string)), sql.CreateCredentialStatements(credential)...)
+  } else if diff.HasChanges(identityProp, secretWoVersionProp) {
+    statements = sql.UpdateCredentialStatements(credential)
+  } else {
+    return nil
+  }
+
+  return diff.SetNew(previewSqlProp, statements)
+}
+
+func setCredential(data *schema.ResourceData, credential *model.Credential) error {
+  if err := data.Set(credentialIdProp, credential.CredentialID); err != nil {
+    return err
+  }
+  if err := data.Set(credentialNameProp, credential.Name); err != nil {
+    return err
+  }
+  return data.Set(identityProp, credential.Identity)
+}
+
+func resourceCredentialImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+  logger := loggerFromMeta(meta, "credential", "import")
+  logger.Debug().Msgf("Import %s", data.Id())
+
+  server, u, err := serverFromId(data.Id())
+  if err != nil {
+    return nil, err
+  }
+  if err = data.Set(serverProp, server); err != nil {
+    return nil, err
+  }
+
+  parts := strings.Split(u.Path, "/")
+  if len(parts) != 2 {
+    return nil, errors.New("invalid ID")
+  }
+  if err = data.Set(credentialNameProp, parts[1]); err != nil {
+    return nil, err
+  }
+
+  data.SetId(getCredentialID(data))
+
+  name := data.Get(credentialNameProp).(string)
+
+  connector, err := getCredentialConnector(meta, data)
+  if err != nil {
+    return nil, err
+  }
+
+  credential, err := connector.GetCredential(ctx, name)
+  if err != nil {
+    return nil, errors.Wrapf(err, "unable to read credential [%s] for import", name)
+  }
+
+  if credential == nil {
+    return nil, errors.Errorf("no credential [%s] found for import", name)
+  }
+
+  if err = setCredential(data, credential); err != nil {
+    return nil, err
+  }
+
+  return []*schema.ResourceData{data}, nil
+}
+
+func getCredentialConnector(meta interface{}, data *schema.ResourceData) (CredentialConnector, error) {
+  provider := meta.(model.Provider)
+  connector, err := provider.GetConnector(serverProp, data)
+  if err != nil {
+    return nil, err
+  }
+  return connector.(CredentialConnector), nil
+}
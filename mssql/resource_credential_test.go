@@ -0,0 +1,147 @@
+package mssql
+
+import (
+  "fmt"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+  "regexp"
+  "testing"
+)
+
+func TestAccCredential_Local_Basic(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckCredentialDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckCredential(t, "basic", map[string]interface{}{"name": "credential_basic", "identity": "basic_identity", "secret_wo": "secretIsH8kd$¡", "secret_wo_version": "1"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckCredentialExists("mssql_credential.basic", "basic_identity"),
+          resource.TestCheckResourceAttr("mssql_credential.basic", "name", "credential_basic"),
+          resource.TestCheckResourceAttr("mssql_credential.basic", "identity", "basic_identity"),
+          resource.TestCheckNoResourceAttr("mssql_credential.basic", "secret_wo"),
+          resource.TestCheckResourceAttrSet("mssql_credential.basic", "credential_id"),
+        ),
+      },
+      {
+        Config:      testAccCheckCredential(t, "basic", map[string]interface{}{"name": "credential_basic", "identity": "other_identity", "secret_wo": "secretIsH8kd$¡", "secret_wo_version": "1"}),
+        ExpectError: regexp.MustCompile("changing identity requires a new secret_wo_version"),
+      },
+      {
+        Config: testAccCheckCredential(t, "basic", map[string]interface{}{"name": "credential_basic", "identity": "other_identity", "secret_wo": "otherIsH8kd$¡", "secret_wo_version": "2"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckCredentialExists("mssql_credential.basic", "other_identity"),
+          resource.TestCheckResourceAttr("mssql_credential.basic", "identity", "other_identity"),
+        ),
+      },
+    },
+  })
+}
+
+func TestAccCredential_Local_Login(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy: func(state *terraform.State) error {
+      if err := testAccCheckLoginDestroy(state); err != nil {
+        return err
+      }
+      return testAccCheckCredentialDestroy(state)
+    },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckCredential(t, "mapped", map[string]interface{}{"name": "credential_mapped", "identity": "mapped_identity"}) +
+          testAccCheckLogin(t, "mapped", false, map[string]interface{}{"login_name": "login_mapped", "password": "valueIsH8kd$¡", "credentials": "[mssql_credential.mapped.name]"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login.mapped"),
+          resource.TestCheckResourceAttr("mssql_login.mapped", "credentials.#", "1"),
+          resource.TestCheckTypeSetElemAttr("mssql_login.mapped", "credentials.*", "credential_mapped"),
+        ),
+      },
+      {
+        Config: testAccCheckCredential(t, "mapped", map[string]interface{}{"name": "credential_mapped", "identity": "mapped_identity"}) +
+          testAccCheckLogin(t, "mapped", false, map[string]interface{}{"login_name": "login_mapped", "password": "valueIsH8kd$¡", "credentials": "[]"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.mapped", "credentials.#", "0"),
+        ),
+      },
+    },
+  })
+}
+
+func testAccCheckCredential(t *testing.T, name string, data map[string]interface{}) string {
+  text := `resource "mssql_credential" "{{ .name }}" {
+             server {
+               host = "localhost"
+               login {}
+             }
+             name     = "{{ .credential_name }}"
+             identity = "{{ .identity }}"
+             {{ with .secret_wo }}secret_wo = "{{ . }}"{{ end }}
+             {{ with .secret_wo_version }}secret_wo_version = {{ . }}{{ end }}
+           }
+           `
+  data["credential_name"] = data["name"]
+  data["name"] = name
+  res, err := templateToString(name, text, data)
+  if err != nil {
+    t.Fatalf("%s", err)
+  }
+  return res
+}
+
+func testAccCheckCredentialDestroy(state *terraform.State) error {
+  for _, rs := range state.RootModule().Resources {
+    if rs.Type != "mssql_credential" {
+      continue
+    }
+
+    connector, err := getTestConnector(rs.Primary.Attributes)
+    if err != nil {
+      return err
+    }
+
+    credential, err := connector.GetCredential(rs.Primary.Attributes[credentialNameProp])
+    if credential != nil {
+      return fmt.Errorf("credential still exists")
+    }
+    if err != nil {
+      return fmt.Errorf("expected no error, got %s", err)
+    }
+  }
+  return nil
+}
+
+func testAccCheckCredentialExists(resource string, identity string) resource.TestCheckFunc {
+  return func(state *terraform.State) error {
+    rs, ok := state.RootModule().Resources[resource]
+    if !ok {
+      return fmt.Errorf("not found: %s", resource)
+    }
+    if rs.Type != "mssql_credential" {
+      return fmt.Errorf("expected resource of type %s, got %s", "mssql_credential", rs.Type)
+    }
+    if rs.Primary.ID == "" {
+      return fmt.Errorf("no record ID is set")
+    }
+    connector, err := getTestConnector(rs.Primary.Attributes)
+    if err != nil {
+      return err
+    }
+
+    credential, err := connector.GetCredential(rs.Primary.Attributes[credentialNameProp])
+    if credential == nil {
+      return fmt.Errorf("credential does not exist")
+    }
+    if err != nil {
+      return fmt.Errorf("expected no error, got %s", err)
+    }
+    if credential.Identity != identity {
+      return fmt.Errorf("expected identity %s, got %s", identity, credential.Identity)
+    }
+    return nil
+  }
+}
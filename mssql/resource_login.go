@@ -2,23 +2,71 @@ package mssql
 
 import (
   "context"
+  "fmt"
   "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
   "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
   "github.com/pkg/errors"
+  "regexp"
   "strings"
   "github.com/betr-io/terraform-provider-mssql/mssql/model"
+  "github.com/betr-io/terraform-provider-mssql/sql"
 )
 
 const loginNameProp = "login_name"
 const defaultDatabaseProp = "default_database"
 const defaultDatabaseDefault = "master"
 const defaultLanguageProp = "default_language"
+const loginTypeProp = "type"
+const loginTypeDefault = "sql"
+const certificateNameProp = "certificate_name"
+const asymmetricKeyNameProp = "asymmetric_key_name"
+const checkPolicyProp = "check_policy"
+const checkExpirationProp = "check_expiration"
+const mustChangePasswordProp = "must_change_password"
+const enabledProp = "enabled"
+const denyConnectSqlProp = "deny_connect_sql"
+const passwordHashProp = "password_hash"
+const deleteSessionsProp = "delete_sessions"
+const deleteSessionsDefault = "kill"
+const forceDestroyProp = "force_destroy"
+const serverRolesProp = "server_roles"
+const credentialsProp = "credentials"
+const unlockProp = "unlock"
+const isLockedProp = "is_locked"
+const isExpiredProp = "is_expired"
+const badPasswordCountProp = "bad_password_count"
+const passwordLastSetTimeProp = "password_last_set_time"
+const daysUntilExpirationProp = "days_until_expiration"
+
+var loginTypes = []string{"sql", "windows_user", "windows_group", "external_user", "external_group", "certificate", "asymmetric_key"}
+
+// Attributes that only apply to some login types
+var loginTypeAttributes = []struct {
+  prop     string
+  types    []string
+  required bool
+}{
+  {passwordProp, []string{"sql"}, false},
+  {passwordHashProp, []string{"sql"}, false},
+  {sidStrProp, []string{"sql"}, false},
+  {objectIdProp, []string{"external_user", "external_group"}, false},
+  {certificateNameProp, []string{"certificate"}, true},
+  {asymmetricKeyNameProp, []string{"asymmetric_key"}, true},
+}
 
 type LoginConnector interface {
-  CreateLogin(ctx context.Context, name, password, sid, defaultDatabase, defaultLanguage string) error
+  CreateLogin(ctx context.Context, login *model.Login) error
   GetLogin(ctx context.Context, name string) (*model.Login, error)
-  UpdateLogin(ctx context.Context, name, password, defaultDatabase, defaultLanguage string) error
-  DeleteLogin(ctx context.Context, name string) error
+  GetLoginByPrincipalID(ctx context.Context, principalID int64) (*model.Login, error)
+  RenameLogin(ctx context.Context, name, newName string) error
+  LoginPasswordMatches(ctx context.Context, name, password string) (bool, error)
+  UpdateLogin(ctx context.Context, login *model.Login) error
+  CreateLoginStatements(ctx context.Context, login *model.Login) ([]string, error)
+  UpdateLoginStatements(ctx context.Context, old, new *model.Login) ([]string, error)
+  DeleteLogin(ctx context.Context, name, deleteSessions string, force bool) error
+  GetDatabaseNames(ctx context.Context) ([]string, error)
+  GetLanguageNames(ctx context.Context) ([]string, error)
 }
 
 func resourceLogin() *schema.Resource {
@@ -27,6 +75,7 @@ func resourceLogin() *schema.Resource {
     ReadContext:   resourceLoginRead,
     UpdateContext: resourceLoginUpdate,
     DeleteContext: resourceLoginDelete,
+    CustomizeDiff: resourceLoginCustomizeDiff,
     Importer: &schema.ResourceImporter{
       StateContext: resourceLoginImport,
     },
@@ -42,19 +91,94 @@ func resourceLogin() *schema.Resource {
       loginNameProp: {
         Type:     schema.TypeString,
         Required: true,
+      },
+      loginTypeProp: {
+        Type:         schema.TypeString,
+        Optional:     true,
+        ForceNew:     true,
+        Default:      loginTypeDefault,
+        ValidateFunc: validation.StringInSlice(loginTypes, false),
+      },
+      objectIdProp: {
+        Type:     schema.TypeString,
+        Optional: true,
+        ForceNew: true,
+        Computed: true,
+        DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+          return strings.EqualFold(old, new)
+        },
+      },
+      certificateNameProp: {
+        Type:     schema.TypeString,
+        Optional: true,
+        ForceNew: true,
+      },
+      asymmetricKeyNameProp: {
+        Type:     schema.TypeString,
+        Optional: true,
         ForceNew: true,
       },
       passwordProp: {
         Type:      schema.TypeString,
-        Required:  true,
+        Optional:  true,
         Sensitive: true,
       },
+      passwordWoProp: {
+        Type:      schema.TypeString,
+        Optional:  true,
+        Sensitive: true,
+        WriteOnly: true,
+      },
+      passwordWoVersionProp: {
+        Type:     schema.TypeInt,
+        Optional: true,
+      },
+      passwordHashProp: {
+        Type:         schema.TypeString,
+        Optional:     true,
+        Computed:     true,
+        Sensitive:    true,
+        ValidateFunc: validation.StringMatch(regexp.MustCompile("^0x[0-9A-Fa-f]+$"), "must be a hexadecimal string starting with 0x"),
+        DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+          return strings.EqualFold(old, new)
+        },
+      },
       sidStrProp: {
         Type:     schema.TypeString,
         Optional: true,
         ForceNew: true,
         Computed: true,
       },
+      checkPolicyProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  true,
+      },
+      checkExpirationProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+      mustChangePasswordProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+      unlockProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+      enabledProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  true,
+      },
+      denyConnectSqlProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
       defaultDatabaseProp: {
         Type:     schema.TypeString,
         Optional: true,
@@ -70,47 +194,178 @@ func resourceLogin() *schema.Resource {
           return (old == "" && new == "us_english") || (old == "us_english" && new == "")
         },
       },
+      serverRolesProp: {
+        Type:     schema.TypeSet,
+        Optional: true,
+        Computed: true,
+        Elem: &schema.Schema{
+          Type: schema.TypeString,
+        },
+      },
+      credentialsProp: {
+        Type:     schema.TypeSet,
+        Optional: true,
+        Computed: true,
+        Elem: &schema.Schema{
+          Type: schema.TypeString,
+        },
+      },
+      deleteSessionsProp: {
+        Type:         schema.TypeString,
+        Optional:     true,
+        Default:      deleteSessionsDefault,
+        ValidateFunc: validation.StringInSlice([]string{"kill", "wait", "fail"}, false),
+      },
+      forceDestroyProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+      adoptExistingProp: {
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
       principalIdProp: {
         Type:     schema.TypeInt,
         Computed: true,
       },
+      isLockedProp: {
+        Type:     schema.TypeBool,
+        Computed: true,
+      },
+      isExpiredProp: {
+        Type:     schema.TypeBool,
+        Computed: true,
+      },
+      badPasswordCountProp: {
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      passwordLastSetTimeProp: {
+        Type:     schema.TypeString,
+        Computed: true,
+      },
+      daysUntilExpirationProp: {
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      previewSqlProp: previewSqlSchema(),
     },
     Timeouts: &schema.ResourceTimeout{
       Default: defaultTimeout,
       Read: defaultTimeout,
+      Delete: defaultTimeout,
     },
   }
 }
 
 func resourceLoginCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
   logger := loggerFromMeta(meta, "login", "create")
-  logger.Debug().Msgf("Create %s", getLoginID(data))
+  logger.Debug().Msgf("Create %s", data.Get(loginNameProp))
 
   loginName := data.Get(loginNameProp).(string)
-  password := data.Get(passwordProp).(string)
-  sid := data.Get(sidStrProp).(string)
-  defaultDatabase := data.Get(defaultDatabaseProp).(string)
-  defaultLanguage := data.Get(defaultLanguageProp).(string)
+
+  login := &model.Login{
+    LoginName:          loginName,
+    Password:           data.Get(passwordProp).(string),
+    PasswordHash:       data.Get(passwordHashProp).(string),
+    Type:               data.Get(loginTypeProp).(string),
+    SIDStr:             data.Get(sidStrProp).(string),
+    ObjectId:           data.Get(objectIdProp).(string),
+    CertificateName:    data.Get(certificateNameProp).(string),
+    AsymmetricKeyName:  data.Get(asymmetricKeyNameProp).(string),
+    DefaultDatabase:    data.Get(defaultDatabaseProp).(string),
+    DefaultLanguage:    data.Get(defaultLanguageProp).(string),
+    CheckPolicy:        data.Get(checkPolicyProp).(bool),
+    CheckExpiration:    data.Get(checkExpirationProp).(bool),
+    MustChangePassword: data.Get(mustChangePasswordProp).(bool),
+    Enabled:            data.Get(enabledProp).(bool),
+    DenyConnectSql:     data.Get(denyConnectSqlProp).(bool),
+    ServerRoles:        configuredNames(data, serverRolesProp),
+    Credentials:        configuredNames(data, credentialsProp),
+  }
+  if login.Password == "" {
+    login.Password = getWriteOnly(data, passwordWoProp)
+  }
 
   connector, err := getLoginConnector(meta, data)
   if err != nil {
     return diag.FromErr(err)
   }
 
-  if err = connector.CreateLogin(ctx, loginName, password, sid, defaultDatabase, defaultLanguage); err != nil {
-    return diag.FromErr(errors.Wrapf(err, "unable to create login [%s]", loginName))
+  if isDryRun(meta) {
+    statements, err := connector.CreateLoginStatements(ctx, login)
+    if err != nil {
+      return diag.FromErr(err)
+    }
+    return dryRunDiagnostics(fmt.Sprintf("create login [%s]", loginName), statements)
   }
 
-  data.SetId(getLoginID(data))
+  warnings := loginNameWarnings(ctx, connector, data)
 
-  logger.Info().Msgf("created login [%s]", loginName)
+  // An existing login is looked up and checked before anything is changed, so that a login that cannot be adopted is
+  // left as it is
+  var existing *model.Login
+  if data.Get(adoptExistingProp).(bool) {
+    if existing, err = connector.GetLogin(ctx, loginName); err != nil {
+      return sqlDiagnostics(err, loginNameProp, "unable to read login [%s]", loginName)
+    }
+  }
+  if existing != nil {
+    if err = adoptLogin(ctx, connector, existing, login); err != nil {
+      return sqlDiagnostics(err, loginNameProp, "unable to adopt login [%s]", loginName)
+    }
+    logger.Info().Msgf("adopted existing login [%s]", loginName)
+  } else {
+    if err = connector.CreateLogin(ctx, login); err != nil {
+      return sqlDiagnostics(err, loginNameProp, "unable to create login [%s]", loginName)
+    }
+    logger.Info().Msgf("created login [%s]", loginName)
+  }
+
+  if err = setLoginID(ctx, connector, data); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to read login [%s]", loginName)
+  }
+
+  return append(warnings, resourceLoginRead(ctx, data, meta)...)
+}
 
-  return resourceLoginRead(ctx, data, meta)
+// Takes over the existing login if it is of the same type and SID as login, and reconciles its attributes to login
+func adoptLogin(ctx context.Context, connector LoginConnector, existing, login *model.Login) error {
+  if existing.Type != login.Type {
+    return errors.Errorf("existing login is of type %s, not %s", existing.Type, login.Type)
+  }
+  if login.SIDStr != "" && !strings.EqualFold(existing.SIDStr, login.SIDStr) {
+    return errors.Errorf("existing login has SID %s, not %s", existing.SIDStr, login.SIDStr)
+  }
+  return connector.UpdateLogin(ctx, login)
+}
+
+// Identifies the resource by the principal id of the login just created, along with the SID the login is checked against
+// when it is read
+func setLoginID(ctx context.Context, connector LoginConnector, data *schema.ResourceData) error {
+  loginName := data.Get(loginNameProp).(string)
+  login, err := connector.GetLogin(ctx, loginName)
+  if err != nil {
+    return err
+  }
+  if login == nil {
+    return errors.Errorf("login [%s] not found", loginName)
+  }
+  if err = data.Set(principalIdProp, login.PrincipalID); err != nil {
+    return err
+  }
+  if err = data.Set(sidStrProp, login.SIDStr); err != nil {
+    return err
+  }
+  data.SetId(getLoginID(data))
+  return nil
 }
 
 func resourceLoginRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
   logger := loggerFromMeta(meta, "login", "read")
-  logger.Debug().Msgf("Read %s", getLoginID(data))
+  logger.Debug().Msgf("Read %s", data.Id())
 
   loginName := data.Get(loginNameProp).(string)
 
@@ -119,9 +374,9 @@ func resourceLoginRead(ctx context.Context, data *schema.ResourceData, meta inte
     return diag.FromErr(err)
   }
 
-  login, err := connector.GetLogin(ctx, loginName)
+  login, err := findLogin(ctx, connector, data)
   if err != nil {
-    return diag.FromErr(errors.Wrapf(err, "unable to read login [%s]", loginName))
+    return sqlDiagnostics(err, loginNameProp, "unable to read login [%s]", loginName)
   }
   if login == nil {
     logger.Info().Msgf("No login found for [%s]", loginName)
@@ -130,6 +385,26 @@ func resourceLoginRead(ctx context.Context, data *schema.ResourceData, meta inte
     if err = data.Set(principalIdProp, login.PrincipalID); err != nil {
       return diag.FromErr(err)
     }
+    // Replaces the IDs of earlier versions, which held the login name
+    data.SetId(getLoginID(data))
+    if err = data.Set(loginNameProp, login.LoginName); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = data.Set(loginTypeProp, login.Type); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = data.Set(certificateNameProp, login.CertificateName); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = data.Set(asymmetricKeyNameProp, login.AsymmetricKeyName); err != nil {
+      return diag.FromErr(err)
+    }
+    // The SID of service principal logins is derived from the application id, so a configured object id is kept
+    if data.Get(objectIdProp).(string) == "" {
+      if err = data.Set(objectIdProp, login.ObjectId); err != nil {
+        return diag.FromErr(err)
+      }
+    }
     if err = data.Set(sidStrProp, login.SIDStr); err != nil {
       return diag.FromErr(err)
     }
@@ -139,32 +414,105 @@ func resourceLoginRead(ctx context.Context, data *schema.ResourceData, meta inte
     if err = data.Set(defaultLanguageProp, login.DefaultLanguage); err != nil {
       return diag.FromErr(err)
     }
+    if err = data.Set(enabledProp, login.Enabled); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = data.Set(denyConnectSqlProp, login.DenyConnectSql); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = data.Set(serverRolesProp, login.ServerRoles); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = data.Set(credentialsProp, login.Credentials); err != nil {
+      return diag.FromErr(err)
+    }
+    if err = setLoginProperties(data, login); err != nil {
+      return diag.FromErr(err)
+    }
+    // The password policy only applies to SQL Server authentication logins
+    if login.Type == "sql" {
+      if err = data.Set(checkPolicyProp, login.CheckPolicy); err != nil {
+        return diag.FromErr(err)
+      }
+      if err = data.Set(checkExpirationProp, login.CheckExpiration); err != nil {
+        return diag.FromErr(err)
+      }
+      if err = data.Set(passwordHashProp, login.PasswordHash); err != nil {
+        return diag.FromErr(err)
+      }
+      // A login that must change its password is expected to change it outside of Terraform
+      if password := data.Get(passwordProp).(string); password != "" && !data.Get(mustChangePasswordProp).(bool) {
+        matches, err := connector.LoginPasswordMatches(ctx, login.LoginName, password)
+        if err != nil {
+          return sqlDiagnostics(err, passwordProp, "unable to read password of login [%s]", login.LoginName)
+        }
+        // Clearing the password makes the next apply reset it to the configured value
+        if !matches {
+          logger.Info().Msgf("password of login [%s] was changed outside of Terraform", login.LoginName)
+          if err = data.Set(passwordProp, ""); err != nil {
+            return diag.FromErr(err)
+          }
+        }
+      }
+    }
   }
 
   return nil
 }
 
+// Looks up the login of the resource. The login is tracked by its principal id once created, so that a login renamed
+// outside of Terraform is still found.
+func findLogin(ctx context.Context, connector LoginConnector, data *schema.ResourceData) (*model.Login, error) {
+  principalID := data.Get(principalIdProp).(int)
+  if principalID == 0 {
+    return connector.GetLogin(ctx, data.Get(loginNameProp).(string))
+  }
+  login, err := connector.GetLoginByPrincipalID(ctx, int64(principalID))
+  // Principal ids of dropped logins are reused, so a login with another SID is not this login
+  if login != nil && !strings.EqualFold(login.SIDStr, data.Get(sidStrProp).(string)) {
+    return nil, err
+  }
+  return login, err
+}
+
 func resourceLoginUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
   logger := loggerFromMeta(meta, "login", "update")
   logger.Debug().Msgf("Update %s", data.Id())
 
   loginName := data.Get(loginNameProp).(string)
-  password := data.Get(passwordProp).(string)
-  defaultDatabase := data.Get(defaultDatabaseProp).(string)
-  defaultLanguage := data.Get(defaultLanguageProp).(string)
+
+  old, login := loginChange(data)
 
   connector, err := getLoginConnector(meta, data)
   if err != nil {
     return diag.FromErr(err)
   }
 
-  if err = connector.UpdateLogin(ctx, loginName, password, defaultDatabase, defaultLanguage); err != nil {
-    return diag.FromErr(errors.Wrapf(err, "unable to update login [%s]", loginName))
+  if isDryRun(meta) {
+    statements, err := connector.UpdateLoginStatements(ctx, old, login)
+    if err != nil {
+      return diag.FromErr(err)
+    }
+    return append(dryRunDiagnostics(fmt.Sprintf("update login [%s]", loginName), statements), keepPriorState(data, resourceLogin().Schema)...)
+  }
+
+  warnings := loginNameWarnings(ctx, connector, data)
+
+  if data.HasChange(loginNameProp) {
+    oldName, _ := data.GetChange(loginNameProp)
+    if err = connector.RenameLogin(ctx, oldName.(string), loginName); err != nil {
+      return sqlDiagnostics(err, loginNameProp, "unable to rename login [%s] to [%s]", oldName, loginName)
+    }
+    logger.Info().Msgf("renamed login [%s] to [%s]", oldName, loginName)
+  }
+
+  if err = connector.UpdateLogin(ctx, login); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to update login [%s]", loginName)
   }
 
   logger.Info().Msgf("updated login [%s]", loginName)
 
-  return resourceLoginRead(ctx, data, meta)
+  return append(warnings, resourceLoginRead(ctx, data, meta)...)
 }
 
 func resourceLoginDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -172,14 +520,20 @@ func resourceLoginDelete(ctx context.Context, data *schema.ResourceData, meta in
   logger.Debug().Msgf("Delete %s", data.Id())
 
   loginName := data.Get(loginNameProp).(string)
+  deleteSessions := data.Get(deleteSessionsProp).(string)
+  forceDestroy := data.Get(forceDestroyProp).(bool)
+
+  if isDryRun(meta) {
+    return dryRunDeleteDiagnostics(fmt.Sprintf("delete login [%s]", loginName), sql.DeleteLoginStatements(loginName, deleteSessions, forceDestroy))
+  }
 
   connector, err := getLoginConnector(meta, data)
   if err != nil {
     return diag.FromErr(err)
   }
 
-  if err = connector.DeleteLogin(ctx, loginName); err != nil {
-    return diag.FromErr(errors.Wrapf(err, "unable to delete login [%s]", loginName))
+  if err = connector.DeleteLogin(ctx, loginName, deleteSessions, forceDestroy); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to delete login [%s]", loginName)
   }
 
   logger.Info().Msgf("deleted login [%s]", loginName)
@@ -190,6 +544,260 @@ func resourceLoginDelete(ctx context.Context, data *schema.ResourceData, meta in
   return nil
 }
 
+func resourceLoginCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+  if err := validateLoginType(diff); err != nil {
+    return err
+  }
+  if err := validateLoginPassword(diff); err != nil {
+    return err
+  }
+  if err := validatePasswordPolicy(diff); err != nil {
+    return err
+  }
+  if err := validateLoginNames(ctx, diff, meta); err != nil {
+    return err
+  }
+
+  // Unlocking takes an update, so one is planned when unlock is set and the login was found locked
+  if diff.Id() != "" && diff.Get(unlockProp).(bool) && diff.Get(isLockedProp).(bool) {
+    if err := diff.SetNew(isLockedProp, false); err != nil {
+      return err
+    }
+  }
+
+  // A new password changes the hash, unless the hash itself is configured
+  if diff.Id() != "" && diff.HasChanges(passwordProp, passwordWoVersionProp) && !isConfigured(diff, passwordHashProp) {
+    if err := diff.SetNewComputed(passwordHashProp); err != nil {
+      return err
+    }
+  }
+
+  if !isDryRun(meta) {
+    return nil
+  }
+
+  for _, key := range []string{loginNameProp, defaultDatabaseProp, defaultLanguageProp, serverRolesProp, credentialsProp} {
+    if !diff.NewValueKnown(key) && isConfigured(diff, key) {
+      return diff.SetNewComputed(previewSqlProp)
+    }
+  }
+
+  old, new := loginChange(diff)
+  var statements []string
+  if diff.Id() == "" {
+    create, err := loginPreview(ctx, diff, meta, func(connector LoginConnector) ([]string, error) {
+      return connector.CreateLoginStatements(ctx, new)
+    })
+    if create == nil || err != nil {
+      return err
+    }
+    statements = create
+  } else if diff.HasChanges(loginTypeProp, sidStrProp, objectIdProp, certificateNameProp, asymmetricKeyNameProp) {
+    // The login is dropped with the policies of the prior state
+    deleteSessions, _ := diff.GetChange(deleteSessionsProp)
+    forceDestroy, _ := diff.GetChange(forceDestroyProp)
+    create, err := loginPreview(ctx, diff, meta, func(connector LoginConnector) ([]string, error) {
+      return connector.CreateLoginStatements(ctx, new)
+    })
+    if create == nil || err != nil {
+      return err
+    }
+    statements = append(sql.DeleteLoginStatements(old.LoginName, deleteSessions.(string), forceDestroy.(bool)), create...)
+  } else if diff.HasChanges(loginNameProp, passwordProp, passwordWoVersionProp, passwordHashProp, defaultDatabaseProp, defaultLanguageProp, checkPolicyProp, checkExpirationProp, mustChangePasswordProp, enabledProp, denyConnectSqlProp, serverRolesProp, credentialsProp, isLockedProp) {
+    update, err := loginPreview(ctx, diff, meta, func(connector LoginConnector) ([]string, error) {
+      return connector.UpdateLoginStatements(ctx, old, new)
+    })
+    if update == nil || err != nil {
+      return err
+    }
+    statements = update
+  } else {
+    return nil
+  }
+
+  return diff.SetNew(previewSqlProp, statements)
+}
+
+func validateLoginType(diff *schema.ResourceDiff) error {
+  loginType := diff.Get(loginTypeProp).(string)
+  for _, attribute := range loginTypeAttributes {
+    if !diff.NewValueKnown(attribute.prop) {
+      continue
+    }
+    value := diff.Get(attribute.prop).(string)
+    applies := false
+    for _, t := range attribute.types {
+      applies = applies || t == loginType
+    }
+    if applies && attribute.required && value == "" {
+      return errors.Errorf("%s is required for logins of %s %s", attribute.prop, loginTypeProp, loginType)
+    }
+    // Computed attributes keep the value read from the server, so only configured values are rejected
+    if !applies && value != "" && diff.HasChange(attribute.prop) {
+      return errors.Errorf("%s cannot be set for logins of %s %s", attribute.prop, loginTypeProp, loginType)
+    }
+  }
+  if loginType == "certificate" || loginType == "asymmetric_key" {
+    if database := diff.Get(defaultDatabaseProp).(string); database != "" && database != defaultDatabaseDefault {
+      return errors.Errorf("%s cannot be set for logins of %s %s", defaultDatabaseProp, loginTypeProp, loginType)
+    }
+    if diff.Get(defaultLanguageProp).(string) != "" {
+      return errors.Errorf("%s cannot be set for logins of %s %s", defaultLanguageProp, loginTypeProp, loginType)
+    }
+  }
+  return nil
+}
+
+func validateLoginPassword(diff *schema.ResourceDiff) error {
+  config := diff.GetRawConfig()
+  if config.IsNull() {
+    return nil
+  }
+  hasPasswordWo := !config.GetAttr(passwordWoProp).IsNull()
+  if !hasPasswordWo && !config.GetAttr(passwordWoVersionProp).IsNull() {
+    return errors.Errorf("%s requires %s", passwordWoVersionProp, passwordWoProp)
+  }
+  if loginType := diff.Get(loginTypeProp).(string); loginType != "sql" {
+    if hasPasswordWo {
+      return errors.Errorf("%s cannot be set for logins of %s %s", passwordWoProp, loginTypeProp, loginType)
+    }
+    return nil
+  }
+  passwords := 0
+  for _, key := range []string{passwordProp, passwordWoProp, passwordHashProp} {
+    if !config.GetAttr(key).IsNull() {
+      passwords++
+    }
+  }
+  if passwords > 1 {
+    return errors.Errorf("only one of %s, %s and %s can be set", passwordProp, passwordWoProp, passwordHashProp)
+  }
+  if passwords == 0 {
+    return errors.Errorf("%s, %s or %s is required for logins of %s sql", passwordProp, passwordWoProp, passwordHashProp, loginTypeProp)
+  }
+  if !config.GetAttr(passwordHashProp).IsNull() && diff.Get(mustChangePasswordProp).(bool) {
+    return errors.Errorf("%s requires %s or %s", mustChangePasswordProp, passwordProp, passwordWoProp)
+  }
+  return nil
+}
+
+func validatePasswordPolicy(diff *schema.ResourceDiff) error {
+  checkPolicy := diff.Get(checkPolicyProp).(bool)
+  checkExpiration := diff.Get(checkExpirationProp).(bool)
+  mustChangePassword := diff.Get(mustChangePasswordProp).(bool)
+  if loginType := diff.Get(loginTypeProp).(string); loginType != "sql" {
+    if !checkPolicy || checkExpiration || mustChangePassword || diff.Get(unlockProp).(bool) {
+      return errors.Errorf("%s, %s, %s and %s cannot be set for logins of %s %s", checkPolicyProp, checkExpirationProp, mustChangePasswordProp, unlockProp, loginTypeProp, loginType)
+    }
+    return nil
+  }
+  if checkExpiration && !checkPolicy {
+    return errors.Errorf("%s requires %s", checkExpirationProp, checkPolicyProp)
+  }
+  if mustChangePassword && !checkExpiration {
+    return errors.Errorf("%s requires %s", mustChangePasswordProp, checkExpirationProp)
+  }
+  return nil
+}
+
+// Checks the default database and language against the server at plan time. The check is skipped when the server
+// cannot be reached, e.g. because it is created in the same apply.
+func validateLoginNames(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+  databases := namesToValidate(diff, defaultDatabaseProp)
+  languages := namesToValidate(diff, defaultLanguageProp)
+  if len(databases)+len(languages) == 0 || !isServerKnown(diff) {
+    return nil
+  }
+
+  connector, err := getLoginDiffConnector(meta, diff)
+  if err != nil {
+    return err
+  }
+
+  return validateNamesAtPlan(ctx, loggerFromMeta(meta, "login", "plan"), loginNameLookups(connector, databases, languages))
+}
+
+// Returns a warning if the default database or language of the login do not exist or cannot be looked up on apply
+func loginNameWarnings(ctx context.Context, connector LoginConnector, data *schema.ResourceData) diag.Diagnostics {
+  return nameWarnings(ctx, loginNameLookups(connector, namesToCheck(data, defaultDatabaseProp), namesToCheck(data, defaultLanguageProp)))
+}
+
+func loginNameLookups(connector LoginConnector, databases, languages []string) []nameLookup {
+  return []nameLookup{
+    {key: defaultDatabaseProp, kind: "database", values: databases, names: connector.GetDatabaseNames},
+    {key: defaultLanguageProp, kind: "language", values: languages, names: connector.GetLanguageNames},
+  }
+}
+
+func setLoginProperties(data *schema.ResourceData, login *model.Login) error {
+  if err := data.Set(isLockedProp, login.IsLocked); err != nil {
+    return err
+  }
+  if err := data.Set(isExpiredProp, login.IsExpired); err != nil {
+    return err
+  }
+  if err := data.Set(badPasswordCountProp, login.BadPasswordCount); err != nil {
+    return err
+  }
+  if err := data.Set(passwordLastSetTimeProp, login.PasswordLastSetTime); err != nil {
+    return err
+  }
+  return data.Set(daysUntilExpirationProp, login.DaysUntilExpiration)
+}
+
+func loginChange(data changeGetter) (*model.Login, *model.Login) {
+  old, new := &model.Login{}, &model.Login{}
+  o, n := data.GetChange(loginTypeProp)
+  old.Type, new.Type = o.(string), n.(string)
+  o, n = data.GetChange(loginNameProp)
+  old.LoginName, new.LoginName = o.(string), n.(string)
+  o, n = data.GetChange(sidStrProp)
+  old.SIDStr, new.SIDStr = o.(string), n.(string)
+  o, n = data.GetChange(objectIdProp)
+  old.ObjectId, new.ObjectId = o.(string), n.(string)
+  o, n = data.GetChange(certificateNameProp)
+  old.CertificateName, new.CertificateName = o.(string), n.(string)
+  o, n = data.GetChange(asymmetricKeyNameProp)
+  old.AsymmetricKeyName, new.AsymmetricKeyName = o.(string), n.(string)
+  o, n = data.GetChange(defaultDatabaseProp)
+  old.DefaultDatabase, new.DefaultDatabase = o.(string), n.(string)
+  o, n = data.GetChange(defaultLanguageProp)
+  old.DefaultLanguage, new.DefaultLanguage = o.(string), n.(string)
+  o, n = data.GetChange(checkPolicyProp)
+  old.CheckPolicy, new.CheckPolicy = o.(bool), n.(bool)
+  o, n = data.GetChange(checkExpirationProp)
+  old.CheckExpiration, new.CheckExpiration = o.(bool), n.(bool)
+  o, n = data.GetChange(mustChangePasswordProp)
+  old.MustChangePassword, new.MustChangePassword = o.(bool), n.(bool)
+  o, n = data.GetChange(passwordProp)
+  old.Password, new.Password = o.(string), n.(string)
+  if new.Password == "" && (data.Id() == "" || data.HasChanges(passwordWoVersionProp)) {
+    new.Password = getWriteOnly(data, passwordWoProp)
+  }
+  o, n = data.GetChange(passwordHashProp)
+  old.PasswordHash, new.PasswordHash = o.(string), n.(string)
+  // The hash read back for a login with a password is kept in the state, but only a configured hash is applied
+  if !isConfigured(data, passwordHashProp) {
+    new.PasswordHash = ""
+  }
+  o, n = data.GetChange(enabledProp)
+  old.Enabled, new.Enabled = o.(bool), n.(bool)
+  o, n = data.GetChange(denyConnectSqlProp)
+  old.DenyConnectSql, new.DenyConnectSql = o.(bool), n.(bool)
+  o, _ = data.GetChange(serverRolesProp)
+  old.ServerRoles, new.ServerRoles = toStringSlice(o.(*schema.Set).List()), configuredNames(data, serverRolesProp)
+  o, _ = data.GetChange(credentialsProp)
+  old.Credentials, new.Credentials = toStringSlice(o.(*schema.Set).List()), configuredNames(data, credentialsProp)
+  o, n = data.GetChange(isLockedProp)
+  old.IsLocked, new.IsLocked = o.(bool), n.(bool)
+  // Whether the login is locked is read from the server when the change is applied
+  o, n = data.GetChange(unlockProp)
+  old.Unlock, new.Unlock = o.(bool), n.(bool)
+  // MUST_CHANGE is only applied together with a new password
+  new.MustChangePassword = new.MustChangePassword && (data.Id() == "" || data.HasChanges(passwordProp, passwordWoVersionProp))
+  return old, new
+}
+
 func resourceLoginImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
   logger := loggerFromMeta(meta, "login", "import")
   logger.Debug().Msgf("Import %s", data.Id())
@@ -210,8 +818,6 @@ func resourceLoginImport(ctx context.Context, data *schema.ResourceData, meta in
     return nil, err
   }
 
-  data.SetId(getLoginID(data))
-
   loginName := data.Get(loginNameProp).(string)
 
   connector, err := getLoginConnector(meta, data)
@@ -231,6 +837,19 @@ func resourceLoginImport(ctx context.Context, data *schema.ResourceData, meta in
   if err = data.Set(principalIdProp, login.PrincipalID); err != nil {
     return nil, err
   }
+  data.SetId(getLoginID(data))
+  if err = data.Set(loginTypeProp, login.Type); err != nil {
+    return nil, err
+  }
+  if err = data.Set(objectIdProp, login.ObjectId); err != nil {
+    return nil, err
+  }
+  if err = data.Set(certificateNameProp, login.CertificateName); err != nil {
+    return nil, err
+  }
+  if err = data.Set(asymmetricKeyNameProp, login.AsymmetricKeyName); err != nil {
+    return nil, err
+  }
   if err = data.Set(sidStrProp, login.SIDStr); err != nil {
     return nil, err
   }
@@ -240,6 +859,48 @@ func resourceLoginImport(ctx context.Context, data *schema.ResourceData, meta in
   if err = data.Set(defaultLanguageProp, login.DefaultLanguage); err != nil {
     return nil, err
   }
+  if err = data.Set(enabledProp, login.Enabled); err != nil {
+    return nil, err
+  }
+  if err = data.Set(denyConnectSqlProp, login.DenyConnectSql); err != nil {
+    return nil, err
+  }
+  if err = data.Set(serverRolesProp, login.ServerRoles); err != nil {
+    return nil, err
+  }
+  if err = data.Set(credentialsProp, login.Credentials); err != nil {
+    return nil, err
+  }
+  if err = setLoginProperties(data, login); err != nil {
+    return nil, err
+  }
+  // Settings that only take effect on apply or destroy start out at their defaults
+  if err = data.Set(mustChangePasswordProp, false); err != nil {
+    return nil, err
+  }
+  if err = data.Set(unlockProp, false); err != nil {
+    return nil, err
+  }
+  if err = data.Set(deleteSessionsProp, deleteSessionsDefault); err != nil {
+    return nil, err
+  }
+  if err = data.Set(forceDestroyProp, false); err != nil {
+    return nil, err
+  }
+  if err = data.Set(adoptExistingProp, false); err != nil {
+    return nil, err
+  }
+  if login.Type == "sql" {
+    if err = data.Set(checkPolicyProp, login.CheckPolicy); err != nil {
+      return nil, err
+    }
+    if err = data.Set(checkExpirationProp, login.CheckExpiration); err != nil {
+      return nil, err
+    }
+    if err = data.Set(passwordHashProp, login.PasswordHash); err != nil {
+      return nil, err
+    }
+  }
 
   return []*schema.ResourceData{data}, nil
 }
@@ -252,3 +913,40 @@ func getLoginConnector(meta interface{}, data *schema.ResourceData) (LoginConnec
   }
   return connector.(LoginConnector), nil
 }
+
+// Returns the configured names of the set attribute key, or nil when the attribute is left out of the configuration, in
+// which case the server roles or credentials of the login are read but not changed
+func configuredNames(data changeGetter, key string) []string {
+  if !isConfigured(data, key) {
+    return nil
+  }
+  _, n := data.GetChange(key)
+  return toStringSlice(n.(*schema.Set).List())
+}
+
+// Returns the T-SQL that statements builds on the planned server, which is rendered for the server's edition and default
+// language, and against the login as it is on the server. The preview is marked unknown, and nil returned, while the
+// server is not known or not reachable.
+func loginPreview(ctx context.Context, diff *schema.ResourceDiff, meta interface{}, statements func(connector LoginConnector) ([]string, error)) ([]string, error) {
+  if !isServerKnown(diff) {
+    return nil, diff.SetNewComputed(previewSqlProp)
+  }
+  connector, err := getLoginDiffConnector(meta, diff)
+  if err != nil {
+    return nil, err
+  }
+  preview, err := statements(connector)
+  if errors.Is(err, sql.ErrServerUnreachable) {
+    return nil, diff.SetNewComputed(previewSqlProp)
+  }
+  return preview, err
+}
+
+func getLoginDiffConnector(meta interface{}, diff *schema.ResourceDiff) (LoginConnector, error) {
+  provider := meta.(model.Provider)
+  connector, err := provider.GetDiffConnector(serverProp, diff)
+  if err != nil {
+    return nil, err
+  }
+  return connector.(LoginConnector), nil
+}
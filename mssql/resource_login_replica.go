@@ -0,0 +1,388 @@
+package mssql
+
+import (
+  "context"
+  "fmt"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+  "github.com/pkg/errors"
+  "regexp"
+  "strings"
+  "github.com/betr-io/terraform-provider-mssql/mssql/model"
+  "github.com/betr-io/terraform-provider-mssql/sql"
+)
+
+// A copy of a SQL Server authentication login on another server, typically a secondary replica of an availability group,
+// with the SID and password hash of the login on the primary
+func resourceLoginReplica() *schema.Resource {
+  login := resourceLogin().Schema
+  return &schema.Resource{
+    CreateContext: resourceLoginReplicaCreate,
+    ReadContext:   resourceLoginReplicaRead,
+    UpdateContext: resourceLoginReplicaUpdate,
+    DeleteContext: resourceLoginReplicaDelete,
+    CustomizeDiff: resourceLoginReplicaCustomizeDiff,
+    Importer: &schema.ResourceImporter{
+      StateContext: resourceLoginReplicaImport,
+    },
+    Schema: map[string]*schema.Schema{
+      serverProp:    login[serverProp],
+      loginNameProp: login[loginNameProp],
+      sidStrProp: {
+        Type:     schema.TypeString,
+        Required: true,
+        ForceNew: true,
+        DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+          return strings.EqualFold(old, new)
+        },
+      },
+      passwordHashProp: {
+        Type:         schema.TypeString,
+        Required:     true,
+        Sensitive:    true,
+        ValidateFunc: validation.StringMatch(regexp.MustCompile("^0x[0-9A-Fa-f]+$"), "must be a hexadecimal string starting with 0x"),
+        DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+          return strings.EqualFold(old, new)
+        },
+      },
+      defaultDatabaseProp: login[defaultDatabaseProp],
+      defaultLanguageProp: login[defaultLanguageProp],
+      checkPolicyProp:     login[checkPolicyProp],
+      checkExpirationProp: login[checkExpirationProp],
+      enabledProp:         login[enabledProp],
+      denyConnectSqlProp:  login[denyConnectSqlProp],
+      serverRolesProp:     login[serverRolesProp],
+      credentialsProp:     login[credentialsProp],
+      deleteSessionsProp:  login[deleteSessionsProp],
+      principalIdProp:     login[principalIdProp],
+      previewSqlProp:      previewSqlSchema(),
+    },
+    Timeouts: &schema.ResourceTimeout{
+      Default: defaultTimeout,
+      Read: defaultTimeout,
+      Delete: defaultTimeout,
+    },
+  }
+}
+
+func resourceLoginReplicaCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "login_replica", "create")
+  logger.Debug().Msgf("Create %s", data.Get(loginNameProp))
+
+  loginName := data.Get(loginNameProp).(string)
+
+  login := loginReplica(data)
+
+  connector, err := getLoginConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  if isDryRun(meta) {
+    statements, err := connector.CreateLoginStatements(ctx, login)
+    if err != nil {
+      return diag.FromErr(err)
+    }
+    return dryRunDiagnostics(fmt.Sprintf("create login [%s]", loginName), statements)
+  }
+
+  warnings := loginNameWarnings(ctx, connector, data)
+
+  if err = connector.CreateLogin(ctx, login); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to create login [%s]", loginName)
+  }
+
+  logger.Info().Msgf("created login [%s]", loginName)
+
+  if err = setLoginID(ctx, connector, data); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to read login [%s]", loginName)
+  }
+
+  return append(warnings, resourceLoginReplicaRead(ctx, data, meta)...)
+}
+
+func resourceLoginReplicaRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "login_replica", "read")
+  logger.Debug().Msgf("Read %s", data.Id())
+
+  loginName := data.Get(loginNameProp).(string)
+
+  connector, err := getLoginConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  login, err := findLogin(ctx, connector, data)
+  if err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to read login [%s]", loginName)
+  }
+  if login == nil || login.Type != "sql" {
+    logger.Info().Msgf("No login found for [%s]", loginName)
+    data.SetId("")
+    return nil
+  }
+
+  if err = setLoginReplica(data, login); err != nil {
+    return diag.FromErr(err)
+  }
+  // Replaces the IDs of earlier versions, which held the login name
+  data.SetId(getLoginID(data))
+
+  return nil
+}
+
+func resourceLoginReplicaUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "login_replica", "update")
+  logger.Debug().Msgf("Update %s", data.Id())
+
+  loginName := data.Get(loginNameProp).(string)
+
+  old, login := loginReplicaChange(data)
+
+  connector, err := getLoginConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  if isDryRun(meta) {
+    statements, err := connector.UpdateLoginStatements(ctx, old, login)
+    if err != nil {
+      return diag.FromErr(err)
+    }
+    return append(dryRunDiagnostics(fmt.Sprintf("update login [%s]", loginName), statements), keepPriorState(data, resourceLoginReplica().Schema)...)
+  }
+
+  warnings := loginNameWarnings(ctx, connector, data)
+
+  if data.HasChange(loginNameProp) {
+    oldName, _ := data.GetChange(loginNameProp)
+    if err = connector.RenameLogin(ctx, oldName.(string), loginName); err != nil {
+      return sqlDiagnostics(err, loginNameProp, "unable to rename login [%s] to [%s]", oldName, loginName)
+    }
+    logger.Info().Msgf("renamed login [%s] to [%s]", oldName, loginName)
+  }
+
+  if err = connector.UpdateLogin(ctx, login); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to update login [%s]", loginName)
+  }
+
+  logger.Info().Msgf("updated login [%s]", loginName)
+
+  return append(warnings, resourceLoginReplicaRead(ctx, data, meta)...)
+}
+
+func resourceLoginReplicaDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+  logger := loggerFromMeta(meta, "login_replica", "delete")
+  logger.Debug().Msgf("Delete %s", data.Id())
+
+  loginName := data.Get(loginNameProp).(string)
+  deleteSessions := data.Get(deleteSessionsProp).(string)
+
+  if isDryRun(meta) {
+    return dryRunDeleteDiagnostics(fmt.Sprintf("delete login [%s]", loginName), sql.DeleteLoginStatements(loginName, deleteSessions, false))
+  }
+
+  connector, err := getLoginConnector(meta, data)
+  if err != nil {
+    return diag.FromErr(err)
+  }
+
+  if err = connector.DeleteLogin(ctx, loginName, deleteSessions, false); err != nil {
+    return sqlDiagnostics(err, loginNameProp, "unable to delete login [%s]", loginName)
+  }
+
+  logger.Info().Msgf("deleted login [%s]", loginName)
+
+  data.SetId("")
+
+  return nil
+}
+
+func resourceLoginReplicaCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+  if checkExpiration := diff.Get(checkExpirationProp).(bool); checkExpiration && !diff.Get(checkPolicyProp).(bool) {
+    return errors.Errorf("%s requires %s", checkExpirationProp, checkPolicyProp)
+  }
+  if err := validateLoginNames(ctx, diff, meta); err != nil {
+    return err
+  }
+
+  if !isDryRun(meta) {
+    return nil
+  }
+
+  for _, key := range []string{loginNameProp, sidStrProp, passwordHashProp, defaultDatabaseProp, defaultLanguageProp, serverRolesProp, credentialsProp} {
+    if !diff.NewValueKnown(key) && isConfigured(diff, key) {
+      return diff.SetNewComputed(previewSqlProp)
+    }
+  }
+
+  old, new := loginReplicaChange(diff)
+  var statements []string
+  if diff.Id() == "" {
+    create, err := loginPreview(ctx, diff, meta, func(connector LoginConnector) ([]string, error) {
+      return connector.CreateLoginStatements(ctx, new)
+    })
+    if create == nil || err != nil {
+      return err
+    }
+    statements = create
+  } else if diff.HasChange(sidStrProp) {
+    deleteSessions, _ := diff.GetChange(deleteSessionsProp)
+    create, err := loginPreview(ctx, diff, meta, func(connector LoginConnector) ([]string, error) {
+      return connector.CreateLoginStatements(ctx, new)
+    })
+    if create == nil || err != nil {
+      return err
+    }
+    statements = append(sql.DeleteLoginStatements(old.LoginName, deleteSessions.(string), false), create...)
+  } else if diff.HasChanges(loginNameProp, passwordHashProp, defaultDatabaseProp, defaultLanguageProp, checkPolicyProp, checkExpirationProp, enabledProp, denyConnectSqlProp, serverRolesProp, credentialsProp) {
+    update, err := loginPreview(ctx, diff, meta, func(connector LoginConnector) ([]string, error) {
+      return connector.UpdateLoginStatements(ctx, old, new)
+    })
+    if update == nil || err != nil {
+      return err
+    }
+    statements = update
+  } else {
+    return nil
+  }
+
+  return diff.SetNew(previewSqlProp, statements)
+}
+
+func loginReplica(data *schema.ResourceData) *model.Login {
+  return &model.Login{
+    LoginName:       data.Get(loginNameProp).(string),
+    PasswordHash:    data.Get(passwordHashProp).(string),
+    Type:            "sql",
+    SIDStr:          data.Get(sidStrProp).(string),
+    DefaultDatabase: data.Get(defaultDatabaseProp).(string),
+    DefaultLanguage: data.Get(defaultLanguageProp).(string),
+    CheckPolicy:     data.Get(checkPolicyProp).(bool),
+    CheckExpiration: data.Get(checkExpirationProp).(bool),
+    Enabled:         data.Get(enabledProp).(bool),
+    DenyConnectSql:  data.Get(denyConnectSqlProp).(bool),
+    ServerRoles:     configuredNames(data, serverRolesProp),
+    Credentials:     configuredNames(data, credentialsProp),
+  }
+}
+
+func loginReplicaChange(data changeGetter) (*model.Login, *model.Login) {
+  old, new := &model.Login{Type: "sql"}, &model.Login{Type: "sql"}
+  o, n := data.GetChange(loginNameProp)
+  old.LoginName, new.LoginName = o.(string), n.(string)
+  o, n = data.GetChange(sidStrProp)
+  old.SIDStr, new.SIDStr = o.(string), n.(string)
+  o, n = data.GetChange(passwordHashProp)
+  old.PasswordHash, new.PasswordHash = o.(string), n.(string)
+  // Setting the hash again would reset the password of the replica, so it is only set when it changes
+  if data.Id() != "" && !data.HasChanges(passwordHashProp) {
+    new.PasswordHash = ""
+  }
+  o, n = data.GetChange(defaultDatabaseProp)
+  old.DefaultDatabase, new.DefaultDatabase = o.(string), n.(string)
+  o, n = data.GetChange(defaultLanguageProp)
+  old.DefaultLanguage, new.DefaultLanguage = o.(string), n.(string)
+  o, n = data.GetChange(checkPolicyProp)
+  old.CheckPolicy, new.CheckPolicy = o.(bool), n.(bool)
+  o, n = data.GetChange(checkExpirationProp)
+  old.CheckExpiration, new.CheckExpiration = o.(bool), n.(bool)
+  o, n = data.GetChange(enabledProp)
+  old.Enabled, new.Enabled = o.(bool), n.(bool)
+  o, n = data.GetChange(denyConnectSqlProp)
+  old.DenyConnectSql, new.DenyConnectSql = o.(bool), n.(bool)
+  o, _ = data.GetChange(serverRolesProp)
+  old.ServerRoles, new.ServerRoles = toStringSlice(o.(*schema.Set).List()), configuredNames(data, serverRolesProp)
+  o, _ = data.GetChange(credentialsProp)
+  old.Credentials, new.Credentials = toStringSlice(o.(*schema.Set).List()), configuredNames(data, credentialsProp)
+  return old, new
+}
+
+// Sets the attributes of the replica from the login read from its server, so that drift of each replica shows in the plan
+func setLoginReplica(data *schema.ResourceData, login *model.Login) error {
+  if err := data.Set(principalIdProp, login.PrincipalID); err != nil {
+    return err
+  }
+  if err := data.Set(loginNameProp, login.LoginName); err != nil {
+    return err
+  }
+  if err := data.Set(sidStrProp, login.SIDStr); err != nil {
+    return err
+  }
+  // The hash cannot be read without CONTROL SERVER, in which case the configured hash is kept
+  if login.PasswordHash != "" {
+    if err := data.Set(passwordHashProp, login.PasswordHash); err != nil {
+      return err
+    }
+  }
+  if err := data.Set(defaultDatabaseProp, login.DefaultDatabase); err != nil {
+    return err
+  }
+  if err := data.Set(defaultLanguageProp, login.DefaultLanguage); err != nil {
+    return err
+  }
+  if err := data.Set(checkPolicyProp, login.CheckPolicy); err != nil {
+    return err
+  }
+  if err := data.Set(checkExpirationProp, login.CheckExpiration); err != nil {
+    return err
+  }
+  if err := data.Set(enabledProp, login.Enabled); err != nil {
+    return err
+  }
+  if err := data.Set(denyConnectSqlProp, login.DenyConnectSql); err != nil {
+    return err
+  }
+  if err := data.Set(serverRolesProp, login.ServerRoles); err != nil {
+    return err
+  }
+  return data.Set(credentialsProp, login.Credentials)
+}
+
+func resourceLoginReplicaImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+  logger := loggerFromMeta(meta, "login_replica", "import")
+  logger.Debug().Msgf("Import %s", data.Id())
+
+  server, u, err := serverFromId(data.Id())
+  if err != nil {
+    return nil, err
+  }
+  if err = data.Set(serverProp, server); err != nil {
+    return nil, err
+  }
+
+  parts := strings.Split(u.Path, "/")
+  if len(parts) != 2 {
+    return nil, errors.New("invalid ID")
+  }
+  if err = data.Set(loginNameProp, parts[1]); err != nil {
+    return nil, err
+  }
+
+  loginName := data.Get(loginNameProp).(string)
+
+  connector, err := getLoginConnector(meta, data)
+  if err != nil {
+    return nil, err
+  }
+
+  login, err := connector.GetLogin(ctx, loginName)
+  if err != nil {
+    return nil, errors.Wrapf(err, "unable to read login [%s] for import", loginName)
+  }
+
+  if login == nil || login.Type != "sql" {
+    return nil, errors.Errorf("no SQL Server authentication login [%s] found for import", loginName)
+  }
+
+  if err = setLoginReplica(data, login); err != nil {
+    return nil, err
+  }
+  data.SetId(getLoginID(data))
+  if err = data.Set(deleteSessionsProp, deleteSessionsDefault); err != nil {
+    return nil, err
+  }
+
+  return []*schema.ResourceData{data}, nil
+}
@@ -0,0 +1,56 @@
+package mssql
+
+import (
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+  "testing"
+)
+
+func TestAccLoginReplica_Local_Basic(t *testing.T) {
+  config := testAccCheckLogin(t, "primary", false, map[string]interface{}{"login_name": "login_primary", "password": "valueIsH8kd$¡"}) + `
+    resource "mssql_login_replica" "secondary" {
+      server {
+        host = "localhost"
+        login {}
+      }
+      login_name    = "login_secondary"
+      sid           = "0xB7BDEF7990D03541BAA2AD73E4FF18E8"
+      password_hash = mssql_login.primary.password_hash
+    }`
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: config,
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login_replica.secondary", Check{"default_database", "==", "master"}),
+          resource.TestCheckResourceAttr("mssql_login_replica.secondary", "sid", "0xB7BDEF7990D03541BAA2AD73E4FF18E8"),
+          resource.TestCheckResourceAttrPair("mssql_login_replica.secondary", "password_hash", "mssql_login.primary", "password_hash"),
+          resource.TestCheckResourceAttrSet("mssql_login_replica.secondary", "principal_id"),
+          resource.TestCheckResourceAttr("mssql_login_replica.secondary", "deny_connect_sql", "false"),
+        ),
+      },
+      {
+        PreConfig:          testAccLocalExec(t, "master", "DENY CONNECT SQL TO [login_secondary]", ""),
+        Config:             config,
+        PlanOnly:           true,
+        ExpectNonEmptyPlan: true,
+      },
+      {
+        PreConfig:          testAccLocalExec(t, "master", "ALTER LOGIN [login_secondary] WITH PASSWORD = 'otherIsH8kd$¡'", ""),
+        Config:             config,
+        PlanOnly:           true,
+        ExpectNonEmptyPlan: true,
+      },
+      {
+        Config: config,
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttrPair("mssql_login_replica.secondary", "password_hash", "mssql_login.primary", "password_hash"),
+        ),
+      },
+    },
+  })
+}
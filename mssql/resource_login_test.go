@@ -5,6 +5,7 @@ import (
   "github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
   "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
   "os"
+  "regexp"
   "testing"
 )
 
@@ -32,6 +33,11 @@ func TestAccLogin_Local_Basic(t *testing.T) {
           resource.TestCheckResourceAttr("mssql_login.basic", "server.0.login.0.password", os.Getenv("MSSQL_PASSWORD")),
           resource.TestCheckResourceAttr("mssql_login.basic", "server.0.azure_login.#", "0"),
           resource.TestCheckResourceAttrSet("mssql_login.basic", "principal_id"),
+          resource.TestCheckResourceAttr("mssql_login.basic", "is_locked", "false"),
+          resource.TestCheckResourceAttr("mssql_login.basic", "is_expired", "false"),
+          resource.TestCheckResourceAttr("mssql_login.basic", "bad_password_count", "0"),
+          resource.TestCheckResourceAttr("mssql_login.basic", "days_until_expiration", "-1"),
+          resource.TestCheckResourceAttrSet("mssql_login.basic", "password_last_set_time"),
         ),
       },
     },
@@ -128,7 +134,67 @@ func TestAccLogin_Azure_Basic_SID(t *testing.T) {
   })
 }
 
+func TestAccLogin_Local_Certificate(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        PreConfig: testAccLocalExec(t, "master",
+          "CREATE CERTIFICATE login_certificate ENCRYPTION BY PASSWORD = 'valueIsH8kd$¡' WITH SUBJECT = 'login_certificate'",
+          "DROP CERTIFICATE login_certificate"),
+        Config: testAccCheckLogin(t, "certificate", false, map[string]interface{}{"login_name": "login_certificate", "type": "certificate", "certificate_name": "login_certificate"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login.certificate"),
+          resource.TestCheckResourceAttr("mssql_login.certificate", "login_name", "login_certificate"),
+          resource.TestCheckResourceAttr("mssql_login.certificate", "type", "certificate"),
+          resource.TestCheckResourceAttr("mssql_login.certificate", "certificate_name", "login_certificate"),
+          resource.TestCheckNoResourceAttr("mssql_login.certificate", "password"),
+          resource.TestCheckResourceAttrSet("mssql_login.certificate", "principal_id"),
+        ),
+      },
+      {
+        ResourceName:      "mssql_login.certificate",
+        ImportState:       true,
+        ImportStateVerify: true,
+        ImportStateIdFunc: testAccImportStateId("mssql_login.certificate", false),
+      },
+    },
+  })
+}
+
+func TestAccLogin_Azure_External(t *testing.T) {
+  clientUser := os.Getenv("TF_ACC_AZURE_USER_CLIENT_USER")
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "external", true, map[string]interface{}{"login_name": clientUser, "type": "external_user"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login.external"),
+          resource.TestCheckResourceAttr("mssql_login.external", "login_name", clientUser),
+          resource.TestCheckResourceAttr("mssql_login.external", "type", "external_user"),
+          resource.TestCheckNoResourceAttr("mssql_login.external", "password"),
+          resource.TestCheckResourceAttrSet("mssql_login.external", "object_id"),
+          resource.TestCheckResourceAttrSet("mssql_login.external", "principal_id"),
+        ),
+      },
+      {
+        ResourceName:      "mssql_login.external",
+        ImportState:       true,
+        ImportStateVerify: true,
+        ImportStateIdFunc: testAccImportStateId("mssql_login.external", true),
+      },
+    },
+  })
+}
+
 func TestAccLogin_Local_UpdateLoginName(t *testing.T) {
+  var principalId string
   resource.Test(t, resource.TestCase{
     PreCheck:          func() { testAccPreCheck(t) },
     IsUnitTest:        runLocalAccTests,
@@ -141,6 +207,10 @@ func TestAccLogin_Local_UpdateLoginName(t *testing.T) {
           resource.TestCheckResourceAttr("mssql_login.test_update", "login_name", "login_update_pre"),
           testAccCheckLoginExists("mssql_login.test_update"),
           testAccCheckLoginWorks("mssql_login.test_update"),
+          func(state *terraform.State) error {
+            principalId = state.RootModule().Resources["mssql_login.test_update"].Primary.Attributes[principalIdProp]
+            return nil
+          },
         ),
       },
       {
@@ -149,6 +219,9 @@ func TestAccLogin_Local_UpdateLoginName(t *testing.T) {
           resource.TestCheckResourceAttr("mssql_login.test_update", "login_name", "login_update_post"),
           testAccCheckLoginExists("mssql_login.test_update"),
           testAccCheckLoginWorks("mssql_login.test_update"),
+          func(state *terraform.State) error {
+            return resource.TestCheckResourceAttr("mssql_login.test_update", principalIdProp, principalId)(state)
+          },
         ),
       },
     }})
@@ -180,6 +253,67 @@ func TestAccLogin_Local_UpdatePassword(t *testing.T) {
     }})
 }
 
+func TestAccLogin_Local_UpdatePasswordWo(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password_wo": "valueIsH8kd$¡", "password_wo_version": "1"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login.test_update"),
+          resource.TestCheckNoResourceAttr("mssql_login.test_update", "password_wo"),
+          resource.TestCheckResourceAttr("mssql_login.test_update", "password", ""),
+          testAccCheckLoginWorksWithPassword("mssql_login.test_update", "valueIsH8kd$¡"),
+        ),
+      },
+      {
+        // A new password without a new version is not applied
+        Config:   testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password_wo": "otherIsH8kd$¡", "password_wo_version": "1"}),
+        PlanOnly: true,
+      },
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password_wo": "otherIsH8kd$¡", "password_wo_version": "2"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "password_wo_version", "2"),
+          testAccCheckLoginWorksWithPassword("mssql_login.test_update", "otherIsH8kd$¡"),
+        ),
+      },
+    }})
+}
+
+func TestAccLogin_Local_PasswordDrift(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "test_drift", false, map[string]interface{}{"login_name": "login_drift", "password": "valueIsH8kd$¡"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login.test_drift"),
+          testAccCheckLoginWorks("mssql_login.test_drift"),
+        ),
+      },
+      {
+        PreConfig:          testAccLocalExec(t, "master", "ALTER LOGIN [login_drift] WITH PASSWORD = 'otherIsH8kd$¡'", ""),
+        Config:             testAccCheckLogin(t, "test_drift", false, map[string]interface{}{"login_name": "login_drift", "password": "valueIsH8kd$¡"}),
+        PlanOnly:           true,
+        ExpectNonEmptyPlan: true,
+      },
+      {
+        Config: testAccCheckLogin(t, "test_drift", false, map[string]interface{}{"login_name": "login_drift", "password": "valueIsH8kd$¡"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_drift", "password", "valueIsH8kd$¡"),
+          testAccCheckLoginWorks("mssql_login.test_drift"),
+        ),
+      },
+    }})
+}
+
 func TestAccLogin_Local_UpdateDefaultDatabase(t *testing.T) {
   resource.Test(t, resource.TestCase{
     PreCheck:          func() { testAccPreCheck(t) },
@@ -232,6 +366,188 @@ func TestAccLogin_Local_UpdateDefaultLanguage(t *testing.T) {
     }})
 }
 
+func TestAccLogin_Local_UnknownNames(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config:      testAccCheckLogin(t, "test_unknown", false, map[string]interface{}{"login_name": "login_unknown", "password": "valueIsH8kd$¡", "default_database": "tempdbb"}),
+        ExpectError: regexp.MustCompile(`database \[tempdbb\] does not exist, did you mean \[tempdb\]\?`),
+      },
+      {
+        Config:      testAccCheckLogin(t, "test_unknown", false, map[string]interface{}{"login_name": "login_unknown", "password": "valueIsH8kd$¡", "default_language": "russain"}),
+        ExpectError: regexp.MustCompile(`language \[russain\] does not exist, did you mean \[russian\]\?`),
+      },
+    }})
+}
+
+func TestAccLogin_Local_UpdatePasswordPolicy(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "check_expiration": "true"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "check_policy", "true"),
+          resource.TestCheckResourceAttr("mssql_login.test_update", "check_expiration", "true"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+          testAccCheckLoginWorks("mssql_login.test_update"),
+        ),
+      },
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "check_policy": "false"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "check_policy", "false"),
+          resource.TestCheckResourceAttr("mssql_login.test_update", "check_expiration", "false"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+          testAccCheckLoginWorks("mssql_login.test_update"),
+        ),
+      },
+      {
+        Config:      testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "must_change_password": "true"}),
+        ExpectError: regexp.MustCompile("must_change_password requires check_expiration"),
+      },
+    }})
+}
+
+func TestAccLogin_Local_UpdateEnabled(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "enabled": "false", "deny_connect_sql": "true"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "enabled", "false"),
+          resource.TestCheckResourceAttr("mssql_login.test_update", "deny_connect_sql", "true"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+        ),
+      },
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "enabled", "true"),
+          resource.TestCheckResourceAttr("mssql_login.test_update", "deny_connect_sql", "false"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+          testAccCheckLoginWorks("mssql_login.test_update"),
+        ),
+      },
+      {
+        PreConfig:          testAccLocalExec(t, "master", "ALTER LOGIN [login_update] DISABLE", ""),
+        Config:             testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡"}),
+        PlanOnly:           true,
+        ExpectNonEmptyPlan: true,
+      },
+    }})
+}
+
+func TestAccLogin_Local_UpdateServerRoles(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "server_roles": "[\"dbcreator\"]"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "server_roles.#", "1"),
+          resource.TestCheckTypeSetElemAttr("mssql_login.test_update", "server_roles.*", "dbcreator"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+        ),
+      },
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "server_roles": "[\"securityadmin\",\"processadmin\"]"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "server_roles.#", "2"),
+          resource.TestCheckTypeSetElemAttr("mssql_login.test_update", "server_roles.*", "securityadmin"),
+          resource.TestCheckTypeSetElemAttr("mssql_login.test_update", "server_roles.*", "processadmin"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+        ),
+      },
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "server_roles.#", "2"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+        ),
+      },
+      {
+        Config: testAccCheckLogin(t, "test_update", false, map[string]interface{}{"login_name": "login_update", "password": "valueIsH8kd$¡", "server_roles": "[]"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.test_update", "server_roles.#", "0"),
+          testAccCheckLoginExists("mssql_login.test_update"),
+        ),
+      },
+    }})
+}
+
+func TestAccLogin_Local_DeleteOwner(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config: testAccCheckLogin(t, "owner", false, map[string]interface{}{"login_name": "login_owner", "password": "valueIsH8kd$¡", "delete_sessions": "fail"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.owner", "delete_sessions", "fail"),
+          resource.TestCheckResourceAttr("mssql_login.owner", "force_destroy", "false"),
+          testAccCheckLoginExists("mssql_login.owner"),
+        ),
+      },
+      {
+        PreConfig: testAccLocalExec(t, "master",
+          "CREATE DATABASE login_owned; ALTER AUTHORIZATION ON DATABASE::login_owned TO login_owner",
+          "DROP DATABASE login_owned"),
+        Config:      testAccCheckLogin(t, "owner", false, map[string]interface{}{"login_name": "login_owner", "password": "valueIsH8kd$¡", "delete_sessions": "fail"}),
+        Destroy:     true,
+        ExpectError: regexp.MustCompile("owns database \\[login_owned\\]"),
+      },
+      {
+        Config: testAccCheckLogin(t, "owner", false, map[string]interface{}{"login_name": "login_owner", "password": "valueIsH8kd$¡", "delete_sessions": "fail", "force_destroy": "true"}),
+        Check: resource.ComposeTestCheckFunc(
+          resource.TestCheckResourceAttr("mssql_login.owner", "force_destroy", "true"),
+        ),
+      },
+    }})
+}
+
+func TestAccLogin_Local_AdoptExisting(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        PreConfig:   testAccLocalExec(t, "master", "CREATE LOGIN [login_adopt] WITH PASSWORD = 'otherIsH8kd$¡', DEFAULT_DATABASE = [tempdb]; ALTER LOGIN [login_adopt] DISABLE", ""),
+        Config:      testAccCheckLogin(t, "adopt", false, map[string]interface{}{"login_name": "login_adopt", "password": "valueIsH8kd$¡"}),
+        ExpectError: regexp.MustCompile("adopt_existing"),
+      },
+      {
+        Config: testAccCheckLogin(t, "adopt", false, map[string]interface{}{"login_name": "login_adopt", "password": "valueIsH8kd$¡", "server_roles": "[\"dbcreator\"]", "adopt_existing": "true"}),
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckLoginExists("mssql_login.adopt"),
+          resource.TestCheckResourceAttr("mssql_login.adopt", "adopt_existing", "true"),
+          resource.TestCheckResourceAttr("mssql_login.adopt", "default_database", "master"),
+          resource.TestCheckResourceAttr("mssql_login.adopt", "enabled", "true"),
+          resource.TestCheckTypeSetElemAttr("mssql_login.adopt", "server_roles.*", "dbcreator"),
+          testAccCheckLoginWorks("mssql_login.adopt"),
+        ),
+      },
+    }})
+}
+
 func TestAccLogin_Azure_UpdateLoginName(t *testing.T) {
   resource.Test(t, resource.TestCase{
     PreCheck:          func() { testAccPreCheck(t) },
@@ -278,6 +594,44 @@ func TestAccLogin_Azure_UpdatePassword(t *testing.T) {
     }})
 }
 
+func TestAccLogin_Local_DryRun(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config:             testAccDryRunProvider + testAccCheckLogin(t, "dry_run", false, map[string]interface{}{"login_name": "login_dry_run", "password": "valueIsH8kd$¡", "default_database": "tempdb"}),
+        ExpectNonEmptyPlan: true,
+        Check: resource.ComposeTestCheckFunc(
+          testAccCheckNotInState("mssql_login.dry_run"),
+          testAccCheckLoginDestroy,
+        ),
+      },
+    },
+  })
+}
+
+func TestAccLogin_Local_WindowsWithPassword(t *testing.T) {
+  resource.Test(t, resource.TestCase{
+    PreCheck:          func() { testAccPreCheck(t) },
+    IsUnitTest:        runLocalAccTests,
+    ProviderFactories: testAccProviders,
+    CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+    Steps: []resource.TestStep{
+      {
+        Config:      testAccCheckLogin(t, "windows", false, map[string]interface{}{"login_name": "DOMAIN\\\\login_windows", "type": "windows_user", "password": "valueIsH8kd$¡"}),
+        ExpectError: regexp.MustCompile("password cannot be set for logins of type windows_user"),
+      },
+      {
+        Config:      testAccCheckLogin(t, "sql", false, map[string]interface{}{"login_name": "login_sql"}),
+        ExpectError: regexp.MustCompile("password is required for logins of type sql"),
+      },
+    },
+  })
+}
+
 func testAccCheckLogin(t *testing.T, name string, azure bool, data map[string]interface{}) string {
   text := `resource "mssql_login" "{{ .name }}" {
              server {
@@ -285,10 +639,24 @@ func testAccCheckLogin(t *testing.T, name string, azure bool, data map[string]in
                {{ if .azure }}azure_login {}{{ else }}login {}{{ end }}
              }
              login_name = "{{ .login_name }}"
-             password   = "{{ .password }}"
+             {{ with .type }}type = "{{ . }}"{{ end }}
+             {{ with .password }}password = "{{ . }}"{{ end }}
+             {{ with .password_wo }}password_wo = "{{ . }}"{{ end }}
+             {{ with .password_wo_version }}password_wo_version = {{ . }}{{ end }}
+             {{ with .certificate_name }}certificate_name = "{{ . }}"{{ end }}
              {{ with .sid }}sid = "{{ . }}"{{ end }}
              {{ with .default_database }}default_database = "{{ . }}"{{ end }}
              {{ with .default_language }}default_language = "{{ . }}"{{ end }}
+             {{ with .check_policy }}check_policy = {{ . }}{{ end }}
+             {{ with .check_expiration }}check_expiration = {{ . }}{{ end }}
+             {{ with .must_change_password }}must_change_password = {{ . }}{{ end }}
+             {{ with .enabled }}enabled = {{ . }}{{ end }}
+             {{ with .deny_connect_sql }}deny_connect_sql = {{ . }}{{ end }}
+             {{ with .delete_sessions }}delete_sessions = "{{ . }}"{{ end }}
+             {{ with .force_destroy }}force_destroy = {{ . }}{{ end }}
+             {{ with .adopt_existing }}adopt_existing = {{ . }}{{ end }}
+             {{ with .server_roles }}server_roles = {{ . }}{{ end }}
+             {{ with .credentials }}credentials = {{ . }}{{ end }}
            }`
   data["name"] = name
   data["azure"] = azure
@@ -306,7 +674,7 @@ func testAccCheckLogin(t *testing.T, name string, azure bool, data map[string]in
 
 func testAccCheckLoginDestroy(state *terraform.State) error {
   for _, rs := range state.RootModule().Resources {
-    if rs.Type != "mssql_login" {
+    if rs.Type != "mssql_login" && rs.Type != "mssql_login_replica" {
       continue
     }
 
@@ -333,7 +701,7 @@ func testAccCheckLoginExists(resource string, checks ...Check) resource.TestChec
     if !ok {
       return fmt.Errorf("not found: %s", resource)
     }
-    if rs.Type != "mssql_login" {
+    if rs.Type != "mssql_login" && rs.Type != "mssql_login_replica" {
       return fmt.Errorf("expected resource of type %s, got %s", "mssql_login", rs.Type)
     }
     if rs.Primary.ID == "" {
@@ -374,6 +742,34 @@ func testAccCheckLoginExists(resource string, checks ...Check) resource.TestChec
   }
 }
 
+// Checks that the login works with password, for logins with a write-only password that is not in the state
+func testAccCheckLoginWorksWithPassword(resource string, password string) resource.TestCheckFunc {
+  return func(state *terraform.State) error {
+    rs, ok := state.RootModule().Resources[resource]
+    if !ok {
+      return fmt.Errorf("not found: %s", resource)
+    }
+    attributes := map[string]string{passwordProp: password}
+    for k, v := range rs.Primary.Attributes {
+      if k != passwordProp {
+        attributes[k] = v
+      }
+    }
+    connector, err := getTestLoginConnector(attributes)
+    if err != nil {
+      return err
+    }
+    systemUser, err := connector.GetSystemUser()
+    if err != nil {
+      return err
+    }
+    if systemUser != attributes[loginNameProp] {
+      return fmt.Errorf("expected to log in as [%s], got [%s]", attributes[loginNameProp], systemUser)
+    }
+    return nil
+  }
+}
+
 func testAccCheckLoginWorks(resource string) resource.TestCheckFunc {
   return func(state *terraform.State) error {
     rs, ok := state.RootModule().Resources[resource]
@@ -2,20 +2,47 @@ package mssql
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/betr-io/terraform-provider-mssql/mssql/model"
+	"github.com/betr-io/terraform-provider-mssql/sql"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
 )
 
+const autoFixOrphanProp = "auto_fix_orphan"
+const supplyOldPasswordProp = "supply_old_password"
+const effectiveRolesProp = "effective_roles"
+const principalTypeProp = "principal_type"
+
+var userAuthenticationTypes = []string{"INSTANCE", "DATABASE", "EXTERNAL", "WINDOWS", "WITHOUT_LOGIN", "CERTIFICATE", "ASYMMETRIC_KEY"}
+
+// Attributes that only apply to some authentication types, when the authentication type is configured
+var userAuthenticationTypeAttributes = []struct {
+	prop     string
+	types    []string
+	required bool
+}{
+	{loginNameProp, []string{"INSTANCE", "WINDOWS"}, false},
+	{passwordProp, []string{"DATABASE"}, false},
+	{passwordWoProp, []string{"DATABASE"}, false},
+	{certificateNameProp, []string{"CERTIFICATE"}, true},
+	{asymmetricKeyNameProp, []string{"ASYMMETRIC_KEY"}, true},
+	{defaultLanguageProp, []string{"DATABASE", "EXTERNAL"}, false},
+	{objectIdProp, []string{"EXTERNAL"}, false},
+	{principalTypeProp, []string{"EXTERNAL"}, false},
+}
+
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceUserCreate,
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
+		CustomizeDiff: resourceUserCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceUserImport,
 		},
@@ -37,31 +64,76 @@ func resourceUser() *schema.Resource {
 			usernameProp: {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			objectIdProp: {
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
 			},
+			principalTypeProp: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"user", "group", "application"}, false),
+				// Users and applications are both created with TYPE=E, so they cannot be told apart on the server
+				DiffSuppressFunc: func(k, old, new string, data *schema.ResourceData) bool {
+					return old == new || (old == "user" && new == "application") || (old == "application" && new == "user")
+				},
+			},
 			loginNameProp: {
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
+				// An orphaned user is read back without a login name, which is only planned as a remap with auto_fix_orphan
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return d.Id() != "" && old == "" && new != "" && d.Get(authenticationTypeProp).(string) == "INSTANCE" && !d.Get(autoFixOrphanProp).(bool)
+				},
+			},
+			autoFixOrphanProp: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
 			},
 			passwordProp: {
 				Type:      schema.TypeString,
 				Optional:  true,
-				ForceNew:  true,
 				Sensitive: true,
 			},
+			supplyOldPasswordProp: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			passwordWoProp: {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				WriteOnly: true,
+			},
+			passwordWoVersionProp: {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
 			sidStrProp: {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
 			authenticationTypeProp: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(userAuthenticationTypes, false),
+			},
+			certificateNameProp: {
 				Type:     schema.TypeString,
-				Computed: true,
+				Optional: true,
+				ForceNew: true,
+			},
+			asymmetricKeyNameProp: {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
 			},
 			principalIdProp: {
 				Type:     schema.TypeInt,
@@ -71,12 +143,18 @@ func resourceUser() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 				Default:  defaultSchemaPropDefault,
+				// Users mapped to a certificate or an asymmetric key have no default schema
+				DiffSuppressFunc: func(k, old, new string, data *schema.ResourceData) bool {
+					authType := data.Get(authenticationTypeProp)
+					return authType == "CERTIFICATE" || authType == "ASYMMETRIC_KEY" || old == new
+				},
 			},
 			defaultLanguageProp: {
 				Type:     schema.TypeString,
 				Optional: true,
 				DiffSuppressFunc: func(k, old, new string, data *schema.ResourceData) bool {
-					return data.Get(authenticationTypeProp) == "INSTANCE" || old == new
+					authType := data.Get(authenticationTypeProp)
+					return (authType != "" && authType != "DATABASE" && authType != "EXTERNAL") || old == new
 				},
 			},
 			rolesProp: {
@@ -86,6 +164,19 @@ func resourceUser() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			effectiveRolesProp: {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			adoptExistingProp: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			previewSqlProp: previewSqlSchema(),
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Default: defaultTimeout,
@@ -97,34 +188,41 @@ func resourceUser() *schema.Resource {
 type UserConnector interface {
 	CreateUser(ctx context.Context, database string, user *model.User) error
 	GetUser(ctx context.Context, database, username string) (*model.User, error)
+	GetUserByPrincipalID(ctx context.Context, database string, principalID int64) (*model.User, error)
+	RenameUser(ctx context.Context, database, username, newUsername string) error
+	RemapUser(ctx context.Context, database, username, loginName string) error
+	UserPasswordMatches(ctx context.Context, database, username, password string) (bool, error)
 	UpdateUser(ctx context.Context, database string, user *model.User) error
+	CreateUserStatements(ctx context.Context, database string, user *model.User) ([]string, error)
+	UpdateUserStatements(ctx context.Context, database string, old, new *model.User) ([]string, error)
 	DeleteUser(ctx context.Context, database, username string) error
+	GetRoleNames(ctx context.Context, database string) ([]string, error)
+	GetSchemaNames(ctx context.Context, database string) ([]string, error)
 }
 
 func resourceUserCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	logger := loggerFromMeta(meta, "user", "create")
-	logger.Debug().Msgf("Create %s", getUserID(data))
+	logger.Debug().Msgf("Create %s", data.Get(usernameProp))
 
 	database := data.Get(databaseProp).(string)
 	username := data.Get(usernameProp).(string)
 	objectId := data.Get(objectIdProp).(string)
+	principalType := data.Get(principalTypeProp).(string)
+	certificateName := data.Get(certificateNameProp).(string)
+	asymmetricKeyName := data.Get(asymmetricKeyNameProp).(string)
 	loginName := data.Get(loginNameProp).(string)
 	password := data.Get(passwordProp).(string)
 	defaultSchema := data.Get(defaultSchemaProp).(string)
 	defaultLanguage := data.Get(defaultLanguageProp).(string)
 	roles := data.Get(rolesProp).(*schema.Set).List()
 
+	if password == "" {
+		password = getWriteOnly(data, passwordWoProp)
+	}
 	if loginName != "" && password != "" {
 		return diag.Errorf(loginNameProp + " and " + passwordProp + " cannot both be set")
 	}
-	var authType string
-	if loginName != "" {
-		authType = "INSTANCE"
-	} else if password != "" {
-		authType = "DATABASE"
-	} else {
-		authType = "EXTERNAL"
-	}
+	authType := userAuthType(data.Get(authenticationTypeProp).(string), loginName, password)
 	if defaultSchema == "" {
 		return diag.Errorf(defaultSchemaProp + " cannot be empty")
 	}
@@ -135,24 +233,93 @@ func resourceUserCreate(ctx context.Context, data *schema.ResourceData, meta int
 	}
 
 	user := &model.User{
-		Username:        username,
-		ObjectId:        objectId,
-		LoginName:       loginName,
-		Password:        password,
-		AuthType:        authType,
-		DefaultSchema:   defaultSchema,
-		DefaultLanguage: defaultLanguage,
-		Roles:           toStringSlice(roles),
+		Username:          username,
+		ObjectId:          objectId,
+		PrincipalType:     principalType,
+		LoginName:         loginName,
+		CertificateName:   certificateName,
+		AsymmetricKeyName: asymmetricKeyName,
+		Password:          password,
+		AuthType:          authType,
+		DefaultSchema:     defaultSchema,
+		DefaultLanguage:   defaultLanguage,
+		Roles:             toStringSlice(roles),
 	}
-	if err = connector.CreateUser(ctx, database, user); err != nil {
-		return diag.FromErr(errors.Wrapf(err, "unable to create user [%s].[%s]", database, username))
+	if isDryRun(meta) {
+		statements, err := connector.CreateUserStatements(ctx, database, user)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		return dryRunDiagnostics(fmt.Sprintf("create user [%s].[%s]", database, username), statements)
 	}
 
-	data.SetId(getUserID(data))
+	warnings := userNameWarnings(ctx, connector, data)
 
-	logger.Info().Msgf("created user [%s].[%s]", database, username)
+	// An existing user is looked up and checked before anything is changed, so that a user that cannot be adopted is left
+	// as it is
+	var existing *model.User
+	if data.Get(adoptExistingProp).(bool) {
+		if existing, err = connector.GetUser(ctx, database, username); err != nil {
+			return sqlDiagnostics(err, usernameProp, "unable to read user [%s].[%s]", database, username)
+		}
+	}
+	if existing != nil {
+		if err = adoptUser(ctx, connector, database, existing, user); err != nil {
+			return sqlDiagnostics(err, usernameProp, "unable to adopt user [%s].[%s]", database, username)
+		}
+		logger.Info().Msgf("adopted existing user [%s].[%s]", database, username)
+	} else {
+		if err = connector.CreateUser(ctx, database, user); err != nil {
+			return sqlDiagnostics(err, usernameProp, "unable to create user [%s].[%s]", database, username)
+		}
+		logger.Info().Msgf("created user [%s].[%s]", database, username)
+	}
 
-	return resourceUserRead(ctx, data, meta)
+	if err = setUserID(ctx, connector, data); err != nil {
+		return sqlDiagnostics(err, usernameProp, "unable to read user [%s].[%s]", database, username)
+	}
+
+	return append(warnings, resourceUserRead(ctx, data, meta)...)
+}
+
+// Takes over an existing user of the same authentication type and login as user, and reconciles its attributes to user
+func adoptUser(ctx context.Context, connector UserConnector, database string, existing, user *model.User) error {
+	// A user for a Windows login is created from login_name alone as a user that authenticates at the server
+	if existing.AuthType != user.AuthType && !(existing.AuthType == "WINDOWS" && user.AuthType == "INSTANCE") {
+		return errors.Errorf("existing user has authentication type %s, not %s", existing.AuthType, user.AuthType)
+	}
+	if user.LoginName != "" && existing.LoginName != "" && !strings.EqualFold(existing.LoginName, user.LoginName) {
+		return errors.Errorf("existing user is mapped to login [%s], not [%s]", existing.LoginName, user.LoginName)
+	}
+	// An orphaned user is mapped to the configured login
+	if user.LoginName != "" && existing.LoginName == "" {
+		if err := connector.RemapUser(ctx, database, user.Username, user.LoginName); err != nil {
+			return err
+		}
+	}
+	return connector.UpdateUser(ctx, database, user)
+}
+
+// Identifies the resource by the principal id of the user just created, along with the SID the user is checked against
+// when it is read
+func setUserID(ctx context.Context, connector UserConnector, data *schema.ResourceData) error {
+	database := data.Get(databaseProp).(string)
+	username := data.Get(usernameProp).(string)
+	user, err := connector.GetUser(ctx, database, username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.Errorf("user [%s].[%s] not found", database, username)
+	}
+	if err = data.Set(principalIdProp, user.PrincipalID); err != nil {
+		return err
+	}
+	if err = data.Set(sidStrProp, user.SIDStr); err != nil {
+		return err
+	}
+	data.SetId(getUserID(data))
+	return nil
 }
 
 func resourceUserRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -167,14 +334,18 @@ func resourceUserRead(ctx context.Context, data *schema.ResourceData, meta inter
 		return diag.FromErr(err)
 	}
 
-	user, err := connector.GetUser(ctx, database, username)
+	user, err := findUser(ctx, connector, data)
 	if err != nil {
-		return diag.FromErr(errors.Wrapf(err, "unable to read user [%s].[%s]", database, username))
+		return sqlDiagnostics(err, usernameProp, "unable to read user [%s].[%s]", database, username)
 	}
 	if user == nil {
 		logger.Info().Msgf("No user found for [%s].[%s]", database, username)
 		data.SetId("")
 	} else {
+		username = user.Username
+		if err = data.Set(usernameProp, user.Username); err != nil {
+			return diag.FromErr(err)
+		}
 		if err = data.Set(loginNameProp, user.LoginName); err != nil {
 			return diag.FromErr(err)
 		}
@@ -184,9 +355,24 @@ func resourceUserRead(ctx context.Context, data *schema.ResourceData, meta inter
 		if err = data.Set(authenticationTypeProp, user.AuthType); err != nil {
 			return diag.FromErr(err)
 		}
+		// Users and applications are both of type E, so a configured application is kept
+		if user.PrincipalType == "user" && data.Get(principalTypeProp).(string) == "application" {
+			user.PrincipalType = "application"
+		}
+		if err = data.Set(principalTypeProp, user.PrincipalType); err != nil {
+			return diag.FromErr(err)
+		}
+		if err = data.Set(certificateNameProp, user.CertificateName); err != nil {
+			return diag.FromErr(err)
+		}
+		if err = data.Set(asymmetricKeyNameProp, user.AsymmetricKeyName); err != nil {
+			return diag.FromErr(err)
+		}
 		if err = data.Set(principalIdProp, user.PrincipalID); err != nil {
 			return diag.FromErr(err)
 		}
+		// Replaces the IDs of earlier versions, which held the user name
+		data.SetId(getUserID(data))
 		if err = data.Set(defaultSchemaProp, user.DefaultSchema); err != nil {
 			return diag.FromErr(err)
 		}
@@ -196,41 +382,93 @@ func resourceUserRead(ctx context.Context, data *schema.ResourceData, meta inter
 		if err = data.Set(rolesProp, user.Roles); err != nil {
 			return diag.FromErr(err)
 		}
+		if err = data.Set(effectiveRolesProp, user.EffectiveRoles); err != nil {
+			return diag.FromErr(err)
+		}
+		if password := data.Get(passwordProp).(string); password != "" && user.AuthType == "DATABASE" {
+			matches, err := connector.UserPasswordMatches(ctx, database, user.Username, password)
+			if err != nil {
+				return sqlDiagnostics(err, passwordProp, "unable to read password of user [%s].[%s]", database, user.Username)
+			}
+			// Clearing the password makes the next apply reset it to the configured value
+			if !matches {
+				logger.Info().Msgf("password of user [%s].[%s] was changed outside of Terraform", database, user.Username)
+				if err = data.Set(passwordProp, ""); err != nil {
+					return diag.FromErr(err)
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
+// Finds the user of the resource by its principal id once created, so that a user renamed outside of Terraform is still
+// found, or else by its name
+func findUser(ctx context.Context, connector UserConnector, data *schema.ResourceData) (*model.User, error) {
+	database := data.Get(databaseProp).(string)
+	principalID := data.Get(principalIdProp).(int)
+	if principalID == 0 {
+		return connector.GetUser(ctx, database, data.Get(usernameProp).(string))
+	}
+	user, err := connector.GetUserByPrincipalID(ctx, database, int64(principalID))
+	// Principal ids of dropped users are reused, so a user with another SID is not this user
+	if user != nil && !strings.EqualFold(user.SIDStr, data.Get(sidStrProp).(string)) {
+		return nil, err
+	}
+	return user, err
+}
+
 func resourceUserUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	logger := loggerFromMeta(meta, "user", "update")
 	logger.Debug().Msgf("Update %s", data.Id())
 
 	database := data.Get(databaseProp).(string)
 	username := data.Get(usernameProp).(string)
-	defaultSchema := data.Get(defaultSchemaProp).(string)
-	defaultLanguage := data.Get(defaultLanguageProp).(string)
-	roles := data.Get(rolesProp).(*schema.Set).List()
 
 	connector, err := getUserConnector(meta, data)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	user := &model.User{
-		Username:        username,
-		DefaultSchema:   defaultSchema,
-		DefaultLanguage: defaultLanguage,
-		Roles:           toStringSlice(roles),
+	old, user := userChange(data)
+	// The password is cleared from the state when it was changed outside of Terraform
+	if data.HasChange(passwordProp) && data.Get(supplyOldPasswordProp).(bool) && user.OldPassword == "" {
+		return diag.Errorf("unable to change password of user [%s].[%s]: the old password is unknown, as it was changed outside of Terraform", database, username)
 	}
-	if err = connector.UpdateUser(ctx, database, user); err != nil {
-		return diag.FromErr(errors.Wrapf(err, "unable to update user [%s].[%s]", database, username))
+	if isDryRun(meta) {
+		statements, err := connector.UpdateUserStatements(ctx, database, old, user)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		return append(dryRunDiagnostics(fmt.Sprintf("update user [%s].[%s]", database, username), statements), keepPriorState(data, resourceUser().Schema)...)
 	}
 
-	data.SetId(getUserID(data))
+	warnings := userNameWarnings(ctx, connector, data)
+
+	if data.HasChange(usernameProp) {
+		oldUsername, _ := data.GetChange(usernameProp)
+		if err = connector.RenameUser(ctx, database, oldUsername.(string), username); err != nil {
+			return sqlDiagnostics(err, usernameProp, "unable to rename user [%s].[%s] to [%s]", database, oldUsername, username)
+		}
+		logger.Info().Msgf("renamed user [%s].[%s] to [%s]", database, oldUsername, username)
+	}
+
+	if data.HasChange(loginNameProp) {
+		loginName := data.Get(loginNameProp).(string)
+		if err = connector.RemapUser(ctx, database, username, loginName); err != nil {
+			return sqlDiagnostics(err, loginNameProp, "unable to map user [%s].[%s] to login [%s]", database, username, loginName)
+		}
+		logger.Info().Msgf("mapped user [%s].[%s] to login [%s]", database, username, loginName)
+	}
+
+	if err = connector.UpdateUser(ctx, database, user); err != nil {
+		return sqlDiagnostics(err, usernameProp, "unable to update user [%s].[%s]", database, username)
+	}
 
 	logger.Info().Msgf("updated user [%s].[%s]", database, username)
 
-	return resourceUserRead(ctx, data, meta)
+	return append(warnings, resourceUserRead(ctx, data, meta)...)
 }
 
 func resourceUserDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -240,13 +478,17 @@ func resourceUserDelete(ctx context.Context, data *schema.ResourceData, meta int
 	database := data.Get(databaseProp).(string)
 	username := data.Get(usernameProp).(string)
 
+	if isDryRun(meta) {
+		return dryRunDeleteDiagnostics(fmt.Sprintf("delete user [%s].[%s]", database, username), sql.DeleteUserStatements(username))
+	}
+
 	connector, err := getUserConnector(meta, data)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	if err = connector.DeleteUser(ctx, database, username); err != nil {
-		return diag.FromErr(errors.Wrapf(err, "unable to delete user [%s].[%s]", database, username))
+		return sqlDiagnostics(err, usernameProp, "unable to delete user [%s].[%s]", database, username)
 	}
 
 	logger.Info().Msgf("deleted user [%s].[%s]", database, username)
@@ -257,6 +499,243 @@ func resourceUserDelete(ctx context.Context, data *schema.ResourceData, meta int
 	return nil
 }
 
+func resourceUserCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if err := validateUserPassword(diff); err != nil {
+		return err
+	}
+	if err := validateUserPrincipalType(diff); err != nil {
+		return err
+	}
+	if err := validateUserAuthenticationType(diff); err != nil {
+		return err
+	}
+	if err := validateUserNames(ctx, diff, meta); err != nil {
+		return err
+	}
+	if diff.Id() != "" && diff.HasChange(loginNameProp) && !loginNameRemapsUser(diff) {
+		if err := diff.ForceNew(loginNameProp); err != nil {
+			return err
+		}
+	}
+	if diff.Id() != "" && diff.HasChange(passwordProp) && !passwordChangesInPlace(diff) {
+		if err := diff.ForceNew(passwordProp); err != nil {
+			return err
+		}
+	}
+	if diff.HasChange(rolesProp) {
+		if err := diff.SetNewComputed(effectiveRolesProp); err != nil {
+			return err
+		}
+	}
+
+	if !isDryRun(meta) {
+		return nil
+	}
+
+	for _, key := range []string{databaseProp, usernameProp, objectIdProp, loginNameProp, passwordProp, authenticationTypeProp, certificateNameProp, asymmetricKeyNameProp, defaultSchemaProp, defaultLanguageProp, rolesProp} {
+		if !diff.NewValueKnown(key) {
+			return diff.SetNewComputed(previewSqlProp)
+		}
+	}
+
+	old, new := userChange(diff)
+	var statements []string
+	database := diff.Get(databaseProp).(string)
+	if diff.Id() == "" {
+		create, err := userPreview(ctx, diff, meta, func(connector UserConnector) ([]string, error) {
+			return connector.CreateUserStatements(ctx, database, new)
+		})
+		if create == nil || err != nil {
+			return err
+		}
+		statements = create
+	} else if diff.HasChanges(databaseProp, objectIdProp, principalTypeProp, certificateNameProp, asymmetricKeyNameProp) || (diff.HasChange(authenticationTypeProp) && isConfigured(diff, authenticationTypeProp)) || (diff.HasChange(loginNameProp) && !loginNameRemapsUser(diff)) || (diff.HasChange(passwordProp) && !passwordChangesInPlace(diff)) {
+		create, err := userPreview(ctx, diff, meta, func(connector UserConnector) ([]string, error) {
+			return connector.CreateUserStatements(ctx, database, new)
+		})
+		if create == nil || err != nil {
+			return err
+		}
+		statements = append(sql.DeleteUserStatements(old.Username), create...)
+	} else if diff.HasChanges(usernameProp, loginNameProp, passwordProp, passwordWoVersionProp, defaultSchemaProp, defaultLanguageProp, rolesProp) {
+		update, err := userPreview(ctx, diff, meta, func(connector UserConnector) ([]string, error) {
+			return connector.UpdateUserStatements(ctx, database, old, new)
+		})
+		if update == nil || err != nil {
+			return err
+		}
+		statements = update
+	} else {
+		return nil
+	}
+
+	return diff.SetNew(previewSqlProp, statements)
+}
+
+// Reports whether a change of login name maps the user to another login in place, which is only possible for a user that
+// authenticates at the server or a Windows user. Other users are replaced.
+func loginNameRemapsUser(diff *schema.ResourceDiff) bool {
+	authType, _ := diff.GetChange(authenticationTypeProp)
+	config := diff.GetRawConfig()
+	return userMapsToLogin(authType.(string)) && !config.IsNull() && !config.GetAttr(loginNameProp).IsNull()
+}
+
+// Reports whether a change of password is applied in place, which is only possible for a contained user that keeps a
+// password. Other users are replaced.
+func passwordChangesInPlace(diff *schema.ResourceDiff) bool {
+	authType, _ := diff.GetChange(authenticationTypeProp)
+	config := diff.GetRawConfig()
+	return authType.(string) == "DATABASE" && !config.IsNull() && (!config.GetAttr(passwordProp).IsNull() || !config.GetAttr(passwordWoProp).IsNull())
+}
+
+// Checks the attributes of a user against its configured authentication type. Without one, the authentication type is
+// derived from the login name and password, so a certificate or asymmetric key name cannot be used.
+func validateUserAuthenticationType(diff *schema.ResourceDiff) error {
+	config := diff.GetRawConfig()
+	if config.IsNull() || !config.GetAttr(authenticationTypeProp).IsKnown() {
+		return nil
+	}
+	if config.GetAttr(authenticationTypeProp).IsNull() {
+		for _, key := range []string{certificateNameProp, asymmetricKeyNameProp} {
+			if !config.GetAttr(key).IsNull() {
+				return errors.Errorf("%s requires %s", key, authenticationTypeProp)
+			}
+		}
+		return nil
+	}
+	authType := config.GetAttr(authenticationTypeProp).AsString()
+	for _, attribute := range userAuthenticationTypeAttributes {
+		configured := !config.GetAttr(attribute.prop).IsNull()
+		applies := false
+		for _, t := range attribute.types {
+			applies = applies || t == authType
+		}
+		if applies && attribute.required && !configured {
+			return errors.Errorf("%s is required for users of %s %s", attribute.prop, authenticationTypeProp, authType)
+		}
+		if !applies && configured {
+			return errors.Errorf("%s cannot be set for users of %s %s", attribute.prop, authenticationTypeProp, authType)
+		}
+	}
+	// Unlike a Windows user, which without a login is a contained Windows user, a user that authenticates at the server
+	// requires a login
+	if authType == "INSTANCE" && config.GetAttr(loginNameProp).IsNull() {
+		return errors.Errorf("%s is required for users of %s %s", loginNameProp, authenticationTypeProp, authType)
+	}
+	return nil
+}
+
+// Checks that a principal type is only configured for an external user
+func validateUserPrincipalType(diff *schema.ResourceDiff) error {
+	config := diff.GetRawConfig()
+	if config.IsNull() || config.GetAttr(principalTypeProp).IsNull() {
+		return nil
+	}
+	for _, key := range []string{loginNameProp, passwordProp, passwordWoProp} {
+		if !config.GetAttr(key).IsNull() {
+			return errors.Errorf("%s cannot be set with %s, as it only applies to external users", principalTypeProp, key)
+		}
+	}
+	return nil
+}
+
+func validateUserPassword(diff *schema.ResourceDiff) error {
+	config := diff.GetRawConfig()
+	if config.IsNull() {
+		return nil
+	}
+	hasPasswordWo := !config.GetAttr(passwordWoProp).IsNull()
+	if !hasPasswordWo && !config.GetAttr(passwordWoVersionProp).IsNull() {
+		return errors.Errorf("%s requires %s", passwordWoVersionProp, passwordWoProp)
+	}
+	if hasPasswordWo && !config.GetAttr(passwordProp).IsNull() {
+		return errors.Errorf("%s and %s cannot both be set", passwordProp, passwordWoProp)
+	}
+	if hasPasswordWo && !config.GetAttr(loginNameProp).IsNull() {
+		return errors.Errorf("%s and %s cannot both be set", loginNameProp, passwordWoProp)
+	}
+	// The previous value of a write-only password is not known
+	if supply := config.GetAttr(supplyOldPasswordProp); hasPasswordWo && supply.IsKnown() && !supply.IsNull() && supply.True() {
+		return errors.Errorf("%s cannot be set with %s", supplyOldPasswordProp, passwordWoProp)
+	}
+	return nil
+}
+
+// Checks the roles and default schema against the database at plan time. The check is skipped when the database cannot
+// be reached, e.g. because it is created in the same apply.
+func validateUserNames(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	roles := namesToValidate(diff, rolesProp)
+	schemas := namesToValidate(diff, defaultSchemaProp)
+	if len(roles)+len(schemas) == 0 || !isServerKnown(diff) || !diff.NewValueKnown(databaseProp) {
+		return nil
+	}
+
+	connector, err := getUserDiffConnector(meta, diff)
+	if err != nil {
+		return err
+	}
+
+	return validateNamesAtPlan(ctx, loggerFromMeta(meta, "user", "plan"), userNameLookups(connector, diff.Get(databaseProp).(string), roles, schemas))
+}
+
+// Returns a warning if the roles or default schema of the user do not exist or cannot be looked up on apply
+func userNameWarnings(ctx context.Context, connector UserConnector, data *schema.ResourceData) diag.Diagnostics {
+	return nameWarnings(ctx, userNameLookups(connector, data.Get(databaseProp).(string), namesToCheck(data, rolesProp), namesToCheck(data, defaultSchemaProp)))
+}
+
+func userNameLookups(connector UserConnector, database string, roles, schemas []string) []nameLookup {
+	return []nameLookup{
+		{key: rolesProp, kind: "role", values: roles, names: func(ctx context.Context) ([]string, error) {
+			return connector.GetRoleNames(ctx, database)
+		}},
+		{key: defaultSchemaProp, kind: "schema", values: schemas, names: func(ctx context.Context) ([]string, error) {
+			return connector.GetSchemaNames(ctx, database)
+		}},
+	}
+}
+
+func userChange(data changeGetter) (*model.User, *model.User) {
+	old, new := &model.User{}, &model.User{}
+	o, n := data.GetChange(usernameProp)
+	old.Username, new.Username = o.(string), n.(string)
+	o, n = data.GetChange(objectIdProp)
+	old.ObjectId, new.ObjectId = o.(string), n.(string)
+	o, n = data.GetChange(principalTypeProp)
+	old.PrincipalType, new.PrincipalType = o.(string), n.(string)
+	o, n = data.GetChange(loginNameProp)
+	old.LoginName, new.LoginName = o.(string), n.(string)
+	o, n = data.GetChange(passwordProp)
+	old.Password, new.Password = o.(string), n.(string)
+	if _, supply := data.GetChange(supplyOldPasswordProp); data.Id() != "" && old.Password != new.Password && supply.(bool) {
+		new.OldPassword = old.Password
+	}
+	if new.Password == "" && (data.Id() == "" || data.HasChanges(passwordWoVersionProp)) {
+		new.Password = getWriteOnly(data, passwordWoProp)
+	}
+	o, n = data.GetChange(defaultSchemaProp)
+	old.DefaultSchema, new.DefaultSchema = o.(string), n.(string)
+	o, n = data.GetChange(defaultLanguageProp)
+	old.DefaultLanguage, new.DefaultLanguage = o.(string), n.(string)
+	o, n = data.GetChange(rolesProp)
+	old.Roles, new.Roles = toStringSlice(o.(*schema.Set).List()), toStringSlice(n.(*schema.Set).List())
+	o, n = data.GetChange(certificateNameProp)
+	old.CertificateName, new.CertificateName = o.(string), n.(string)
+	o, n = data.GetChange(asymmetricKeyNameProp)
+	old.AsymmetricKeyName, new.AsymmetricKeyName = o.(string), n.(string)
+	o, n = data.GetChange(authenticationTypeProp)
+	old.AuthType, new.AuthType = o.(string), userAuthType("", new.LoginName, new.Password)
+	if isConfigured(data, authenticationTypeProp) {
+		new.AuthType = n.(string)
+	} else if new.Password == "" && isConfigured(data, passwordWoProp) {
+		new.AuthType = "DATABASE"
+	}
+	// The password of an existing user is only set when it changes
+	if data.Id() != "" && !data.HasChanges(passwordProp, passwordWoVersionProp) {
+		new.Password = ""
+	}
+	return old, new
+}
+
 func resourceUserImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	logger := loggerFromMeta(meta, "user", "import")
 	logger.Debug().Msgf("Import %s", data.Id())
@@ -280,8 +759,6 @@ func resourceUserImport(ctx context.Context, data *schema.ResourceData, meta int
 		return nil, err
 	}
 
-	data.SetId(getUserID(data))
-
 	database := data.Get(databaseProp).(string)
 	username := data.Get(usernameProp).(string)
 
@@ -302,9 +779,16 @@ func resourceUserImport(ctx context.Context, data *schema.ResourceData, meta int
 	if err = data.Set(authenticationTypeProp, login.AuthType); err != nil {
 		return nil, err
 	}
+	if err = data.Set(principalTypeProp, login.PrincipalType); err != nil {
+		return nil, err
+	}
 	if err = data.Set(principalIdProp, login.PrincipalID); err != nil {
 		return nil, err
 	}
+	data.SetId(getUserID(data))
+	if err = data.Set(sidStrProp, login.SIDStr); err != nil {
+		return nil, err
+	}
 	if err = data.Set(defaultSchemaProp, login.DefaultSchema); err != nil {
 		return nil, err
 	}
@@ -314,6 +798,15 @@ func resourceUserImport(ctx context.Context, data *schema.ResourceData, meta int
 	if err = data.Set(rolesProp, login.Roles); err != nil {
 		return nil, err
 	}
+	if err = data.Set(effectiveRolesProp, login.EffectiveRoles); err != nil {
+		return nil, err
+	}
+	// Settings that only take effect on apply start out at their defaults
+	for _, key := range []string{supplyOldPasswordProp, autoFixOrphanProp, adoptExistingProp} {
+		if err = data.Set(key, false); err != nil {
+			return nil, err
+		}
+	}
 
 	return []*schema.ResourceData{data}, nil
 }
@@ -327,6 +820,51 @@ func getUserConnector(meta interface{}, data *schema.ResourceData) (UserConnecto
 	return connector.(UserConnector), nil
 }
 
+// Returns the T-SQL that statements builds on the planned server, which is rendered for the server's edition and default
+// language, and against the user as it is in the database. The preview is marked unknown, and nil returned, while the
+// server is not known or not reachable.
+func userPreview(ctx context.Context, diff *schema.ResourceDiff, meta interface{}, statements func(connector UserConnector) ([]string, error)) ([]string, error) {
+	if !isServerKnown(diff) {
+		return nil, diff.SetNewComputed(previewSqlProp)
+	}
+	connector, err := getUserDiffConnector(meta, diff)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := statements(connector)
+	if errors.Is(err, sql.ErrServerUnreachable) {
+		return nil, diff.SetNewComputed(previewSqlProp)
+	}
+	return preview, err
+}
+
+func getUserDiffConnector(meta interface{}, diff *schema.ResourceDiff) (UserConnector, error) {
+	provider := meta.(model.Provider)
+	connector, err := provider.GetDiffConnector(serverProp, diff)
+	if err != nil {
+		return nil, err
+	}
+	return connector.(UserConnector), nil
+}
+
+// Reports whether users of the authentication type are mapped to a server login, which is optional for Windows users
+func userMapsToLogin(authType string) bool {
+	return authType == "INSTANCE" || authType == "WINDOWS"
+}
+
+// Returns the configured authentication type, or else derives it from the login name and password
+func userAuthType(authType, loginName, password string) string {
+	if authType != "" {
+		return authType
+	}
+	if loginName != "" {
+		return "INSTANCE"
+	} else if password != "" {
+		return "DATABASE"
+	}
+	return "EXTERNAL"
+}
+
 func toStringSlice(values []interface{}) []string {
 	result := make([]string, len(values))
 	for i, v := range values {
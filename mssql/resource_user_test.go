@@ -3,6 +3,7 @@ package mssql
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -131,6 +132,74 @@ func TestAccUser_Azure_Database(t *testing.T) {
 	})
 }
 
+func TestAccUser_Azure_Update_PasswordWo(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUser(t, "database", "azure", map[string]interface{}{"database": "testdb", "username": "database_user", "password_wo": "valueIsH8kd$¡", "password_wo_version": "1"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.database"),
+					testAccCheckDatabaseUserWorks("mssql_user.database", "database_user", "valueIsH8kd$¡"),
+					resource.TestCheckResourceAttr("mssql_user.database", "password", ""),
+					resource.TestCheckResourceAttr("mssql_user.database", "authentication_type", "DATABASE"),
+				),
+			},
+			{
+				Config: testAccCheckUser(t, "database", "azure", map[string]interface{}{"database": "testdb", "username": "database_user", "password_wo": "otherIsH8kd$¡", "password_wo_version": "2"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.database"),
+					testAccCheckDatabaseUserWorks("mssql_user.database", "database_user", "otherIsH8kd$¡"),
+					resource.TestCheckResourceAttr("mssql_user.database", "password_wo_version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUser_Azure_Update_Password(t *testing.T) {
+	var principalId string
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUser(t, "database", "azure", map[string]interface{}{"database": "testdb", "username": "database_user", "password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.database"),
+					testAccCheckDatabaseUserWorks("mssql_user.database", "database_user", "valueIsH8kd$¡"),
+					func(state *terraform.State) error {
+						principalId = state.RootModule().Resources["mssql_user.database"].Primary.Attributes[principalIdProp]
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccCheckUser(t, "database", "azure", map[string]interface{}{"database": "testdb", "username": "database_user", "password": "otherIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.database", Check{"roles", "==", []string{"db_owner"}}),
+					testAccCheckDatabaseUserWorks("mssql_user.database", "database_user", "otherIsH8kd$¡"),
+					func(state *terraform.State) error {
+						return resource.TestCheckResourceAttr("mssql_user.database", principalIdProp, principalId)(state)
+					},
+				),
+			},
+			{
+				Config: testAccCheckUser(t, "database", "azure", map[string]interface{}{"database": "testdb", "username": "database_user", "password": "thirdIsH8kd$¡", "supply_old_password": "true", "roles": "[\"db_owner\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabaseUserWorks("mssql_user.database", "database_user", "thirdIsH8kd$¡"),
+					func(state *terraform.State) error {
+						return resource.TestCheckResourceAttr("mssql_user.database", principalIdProp, principalId)(state)
+					},
+				),
+			},
+		},
+	})
+}
+
 func TestAccUser_AzureadChain_Database(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
@@ -212,6 +281,33 @@ func TestAccUser_Azure_External(t *testing.T) {
 	})
 }
 
+func TestAccUser_Azure_External_Group(t *testing.T) {
+	groupName := os.Getenv("TF_ACC_AZURE_GROUP_NAME")
+	groupObjectId := os.Getenv("TF_ACC_AZURE_GROUP_OBJECT_ID")
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUser(t, "group", "azure", map[string]interface{}{"database": "testdb", "username": groupName, "object_id": groupObjectId, "principal_type": "group"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.group", Check{"authentication_type", "==", "EXTERNAL"}),
+					resource.TestCheckResourceAttr("mssql_user.group", "principal_type", "group"),
+					resource.TestCheckResourceAttr("mssql_user.group", "authentication_type", "EXTERNAL"),
+				),
+			},
+			{
+				ResourceName:            "mssql_user.group",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"object_id"},
+				ImportStateIdFunc:       testAccImportStateId("mssql_user.group", true),
+			},
+		},
+	})
+}
+
 func TestAccUser_AzureadChain_External(t *testing.T) {
 	tenantId := os.Getenv("MSSQL_TENANT_ID")
 	clientId := os.Getenv("TF_ACC_AZURE_USER_CLIENT_ID")
@@ -289,6 +385,265 @@ func TestAccUser_Local_Update_DefaultSchema(t *testing.T) {
 	})
 }
 
+func TestAccUser_Local_AdoptExisting(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				PreConfig: testAccLocalExec(t, "master", "CREATE LOGIN [user_adopt] WITH PASSWORD = 'otherIsH8kd$¡'; CREATE USER [test_adopt] FOR LOGIN [user_adopt] WITH DEFAULT_SCHEMA = [sys]", ""),
+				Config:    testAccCheckUser(t, "adopt", "login", map[string]interface{}{"username": "test_adopt", "login_name": "user_adopt", "login_password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]", "adopt_existing": "true"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.adopt", "adopt_existing", "true"),
+					resource.TestCheckResourceAttr("mssql_user.adopt", "default_schema", "dbo"),
+					testAccCheckUserExists("mssql_user.adopt", Check{"default_schema", "==", "dbo"}, Check{"roles", "==", []string{"db_owner"}}),
+					testAccCheckDatabaseUserWorks("mssql_user.adopt", "user_adopt", "valueIsH8kd$¡"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_Update_Username(t *testing.T) {
+	var principalId string
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUser(t, "update", "login", map[string]interface{}{"username": "test_update_pre", "login_name": "user_update", "login_password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.update", "username", "test_update_pre"),
+					testAccCheckUserExists("mssql_user.update"),
+					func(state *terraform.State) error {
+						principalId = state.RootModule().Resources["mssql_user.update"].Primary.Attributes[principalIdProp]
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccCheckUser(t, "update", "login", map[string]interface{}{"username": "test_update_post", "login_name": "user_update", "login_password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.update", "username", "test_update_post"),
+					testAccCheckUserExists("mssql_user.update", Check{"roles", "==", []string{"db_owner"}}),
+					testAccCheckDatabaseUserWorks("mssql_user.update", "user_update", "valueIsH8kd$¡"),
+					func(state *terraform.State) error {
+						return resource.TestCheckResourceAttr("mssql_user.update", principalIdProp, principalId)(state)
+					},
+					func(state *terraform.State) error {
+						return resource.TestCheckResourceAttr("mssql_user.update", "id", "sqlserver://localhost:1433/master/"+principalId)(state)
+					},
+				),
+			},
+			{
+				PreConfig:          testAccLocalExec(t, "master", "ALTER USER [test_update_post] WITH NAME = [test_update_drift]", ""),
+				Config:             testAccCheckUser(t, "update", "login", map[string]interface{}{"username": "test_update_post", "login_name": "user_update", "login_password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccCheckUser(t, "update", "login", map[string]interface{}{"username": "test_update_post", "login_name": "user_update", "login_password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.update", "username", "test_update_post"),
+					testAccCheckUserExists("mssql_user.update"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_Update_LoginName(t *testing.T) {
+	var principalId string
+	config := func(login string) string {
+		return testAccCheckLogin(t, "remap_pre", false, map[string]interface{}{"login_name": "user_remap_pre", "password": "valueIsH8kd$¡"}) +
+			testAccCheckLogin(t, "remap_post", false, map[string]interface{}{"login_name": "user_remap_post", "password": "valueIsH8kd$¡"}) + `
+          resource "mssql_user" "remap" {
+            server {
+              host = "localhost"
+              login {}
+            }
+            username   = "test_remap"
+            login_name = mssql_login.` + login + `.login_name
+          }`
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: config("remap_pre"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.remap", "login_name", "user_remap_pre"),
+					testAccCheckUserExists("mssql_user.remap", Check{"login_name", "==", "user_remap_pre"}),
+					testAccCheckDatabaseUserWorks("mssql_user.remap", "user_remap_pre", "valueIsH8kd$¡"),
+					func(state *terraform.State) error {
+						principalId = state.RootModule().Resources["mssql_user.remap"].Primary.Attributes[principalIdProp]
+						return nil
+					},
+				),
+			},
+			{
+				Config: config("remap_post"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.remap", "login_name", "user_remap_post"),
+					testAccCheckUserExists("mssql_user.remap", Check{"login_name", "==", "user_remap_post"}),
+					testAccCheckDatabaseUserWorks("mssql_user.remap", "user_remap_post", "valueIsH8kd$¡"),
+					func(state *terraform.State) error {
+						return resource.TestCheckResourceAttr("mssql_user.remap", principalIdProp, principalId)(state)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_AutoFixOrphan(t *testing.T) {
+	var principalId string
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUser(t, "orphan", "login", map[string]interface{}{"username": "test_orphan", "login_name": "user_orphan", "login_password": "valueIsH8kd$¡", "auto_fix_orphan": "false"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.orphan", "auto_fix_orphan", "false"),
+					testAccCheckUserExists("mssql_user.orphan", Check{"login_name", "==", "user_orphan"}),
+					func(state *terraform.State) error {
+						principalId = state.RootModule().Resources["mssql_user.orphan"].Primary.Attributes[principalIdProp]
+						return nil
+					},
+				),
+			},
+			{
+				PreConfig: testAccLocalExec(t, "master",
+					"CREATE LOGIN [user_orphan_tmp] WITH PASSWORD = 'valueIsH8kd$¡'; ALTER USER [test_orphan] WITH LOGIN = [user_orphan_tmp]; DROP LOGIN [user_orphan_tmp]", ""),
+				Config:   testAccCheckUser(t, "orphan", "login", map[string]interface{}{"username": "test_orphan", "login_name": "user_orphan", "login_password": "valueIsH8kd$¡", "auto_fix_orphan": "false"}),
+				PlanOnly: true,
+			},
+			{
+				Config:             testAccCheckUser(t, "orphan", "login", map[string]interface{}{"username": "test_orphan", "login_name": "user_orphan", "login_password": "valueIsH8kd$¡", "auto_fix_orphan": "true"}),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccCheckUser(t, "orphan", "login", map[string]interface{}{"username": "test_orphan", "login_name": "user_orphan", "login_password": "valueIsH8kd$¡", "auto_fix_orphan": "true"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.orphan", "login_name", "user_orphan"),
+					testAccCheckUserExists("mssql_user.orphan", Check{"login_name", "==", "user_orphan"}),
+					testAccCheckDatabaseUserWorks("mssql_user.orphan", "user_orphan", "valueIsH8kd$¡"),
+					func(state *terraform.State) error {
+						return resource.TestCheckResourceAttr("mssql_user.orphan", principalIdProp, principalId)(state)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_WithoutLogin(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUser(t, "without_login", "login", map[string]interface{}{"username": "test_without_login", "authentication_type": "WITHOUT_LOGIN", "default_schema": "sys"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.without_login", Check{"authentication_type", "==", "WITHOUT_LOGIN"}, Check{"default_schema", "==", "sys"}),
+					resource.TestCheckResourceAttr("mssql_user.without_login", "authentication_type", "WITHOUT_LOGIN"),
+					resource.TestCheckResourceAttr("mssql_user.without_login", "login_name", ""),
+				),
+			},
+			{
+				ResourceName:      "mssql_user.without_login",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccImportStateId("mssql_user.without_login", false),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_Certificate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				PreConfig: testAccLocalExec(t, "master",
+					"CREATE CERTIFICATE [user_certificate] ENCRYPTION BY PASSWORD = 'valueIsH8kd$¡' WITH SUBJECT = 'user_certificate'", "DROP CERTIFICATE [user_certificate]"),
+				Config: testAccCheckUser(t, "certificate", "login", map[string]interface{}{"username": "test_certificate", "authentication_type": "CERTIFICATE", "certificate_name": "user_certificate"}),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists("mssql_user.certificate", Check{"authentication_type", "==", "CERTIFICATE"}),
+					resource.TestCheckResourceAttr("mssql_user.certificate", "certificate_name", "user_certificate"),
+					resource.TestCheckResourceAttr("mssql_user.certificate", "login_name", ""),
+				),
+			},
+			{
+				Config:      testAccCheckUser(t, "certificate", "login", map[string]interface{}{"username": "test_certificate", "authentication_type": "CERTIFICATE"}),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("certificate_name is required"),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_Windows(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config:             testAccDryRunProvider + testAccCheckUser(t, "windows", "login", map[string]interface{}{"username": "DOMAIN\\\\user_windows", "authentication_type": "WINDOWS"}),
+				ExpectNonEmptyPlan: true,
+				Check:              testAccCheckNotInState("mssql_user.windows"),
+			},
+			{
+				Config:      testAccCheckUser(t, "windows", "login", map[string]interface{}{"username": "DOMAIN\\\\user_windows", "authentication_type": "WINDOWS", "password": "valueIsH8kd$¡"}),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("password cannot be set for users of authentication_type WINDOWS"),
+			},
+			{
+				Config:      testAccCheckUser(t, "instance", "login", map[string]interface{}{"username": "user_instance", "authentication_type": "INSTANCE"}),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("login_name is required for users of authentication_type INSTANCE"),
+			},
+		},
+	})
+}
+
+func TestAccUser_Local_UnknownNames(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccCheckUser(t, "unknown", "login", map[string]interface{}{"username": "test_unknown", "login_name": "user_unknown", "login_password": "valueIsH8kd$¡", "roles": "[\"db_ownr\"]"}),
+				ExpectError: regexp.MustCompile(`role \[db_ownr\] does not exist, did you mean \[db_owner\]\?`),
+			},
+			{
+				Config:      testAccCheckUser(t, "unknown", "login", map[string]interface{}{"username": "test_unknown", "login_name": "user_unknown", "login_password": "valueIsH8kd$¡", "default_schema": "nonexistent"}),
+				ExpectError: regexp.MustCompile(`schema \[nonexistent\] does not exist`),
+			},
+		},
+	})
+}
+
 func TestAccUser_Local_Update_DefaultLanguage(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
@@ -364,6 +719,29 @@ func TestAccUser_Local_Update_Roles(t *testing.T) {
 	})
 }
 
+func TestAccUser_Local_NestedRoles(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckLoginDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				PreConfig: testAccLocalExec(t, "master", "CREATE ROLE [app_reader]; ALTER ROLE [db_datareader] ADD MEMBER [app_reader]", "DROP ROLE [app_reader]"),
+				Config:    testAccCheckUser(t, "nested", "login", map[string]interface{}{"username": "test_nested", "login_name": "user_nested", "login_password": "valueIsH8kd$¡", "roles": "[\"app_reader\"]"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("mssql_user.nested", "roles.#", "1"),
+					resource.TestCheckTypeSetElemAttr("mssql_user.nested", "roles.*", "app_reader"),
+					resource.TestCheckResourceAttr("mssql_user.nested", "effective_roles.#", "2"),
+					resource.TestCheckTypeSetElemAttr("mssql_user.nested", "effective_roles.*", "app_reader"),
+					resource.TestCheckTypeSetElemAttr("mssql_user.nested", "effective_roles.*", "db_datareader"),
+					testAccCheckUserExists("mssql_user.nested", Check{"roles", "==", []string{"app_reader"}}),
+				),
+			},
+		},
+	})
+}
+
 func TestAccUser_Azure_Update_DefaultSchema(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
@@ -453,6 +831,25 @@ func TestAccUser_Azure_Update_Roles(t *testing.T) {
 	})
 }
 
+func TestAccUser_Local_DryRun(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		IsUnitTest:        runLocalAccTests,
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      func(state *terraform.State) error { return testAccCheckUserDestroy(state) },
+		Steps: []resource.TestStep{
+			{
+				Config:             testAccDryRunProvider + testAccCheckUser(t, "dry_run", "login", map[string]interface{}{"username": "dry_run", "password": "valueIsH8kd$¡", "roles": "[\"db_owner\"]"}),
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckNotInState("mssql_user.dry_run"),
+					testAccCheckUserDestroy,
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckUser(t *testing.T, name string, login string, data map[string]interface{}) string {
 	text := `{{ if .login_name }}
            resource "mssql_login" "{{ .name }}" {
@@ -462,6 +859,7 @@ func testAccCheckUser(t *testing.T, name string, login string, data map[string]i
              }
              login_name = "{{ .login_name }}"
              password   = "{{ .login_password }}"
+             {{ with .adopt_existing }}adopt_existing = {{ . }}{{ end }}
            }
            {{ end }}
            resource "mssql_user" "{{ .name }}" {
@@ -472,10 +870,20 @@ func testAccCheckUser(t *testing.T, name string, login string, data map[string]i
              {{ with .database }}database = "{{ . }}"{{ end }}
              username = "{{ .username }}"
              {{ with .password }}password = "{{ . }}"{{ end }}
+             {{ with .password_wo }}password_wo = "{{ . }}"{{ end }}
+             {{ with .password_wo_version }}password_wo_version = {{ . }}{{ end }}
+             {{ with .object_id }}object_id = "{{ . }}"{{ end }}
+             {{ with .principal_type }}principal_type = "{{ . }}"{{ end }}
+             {{ with .authentication_type }}authentication_type = "{{ . }}"{{ end }}
+             {{ with .certificate_name }}certificate_name = "{{ . }}"{{ end }}
+             {{ with .asymmetric_key_name }}asymmetric_key_name = "{{ . }}"{{ end }}
+             {{ with .supply_old_password }}supply_old_password = {{ . }}{{ end }}
              {{ with .login_name }}login_name = "{{ . }}"{{ end }}
              {{ with .default_schema }}default_schema = "{{ . }}"{{ end }}
              {{ with .default_language }}default_language = "{{ . }}"{{ end }}
              {{ with .roles }}roles = {{ . }}{{ end }}
+             {{ with .adopt_existing }}adopt_existing = {{ . }}{{ end }}
+             {{ with .auto_fix_orphan }}auto_fix_orphan = {{ . }}{{ end }}
            }`
 	data["name"] = name
 	data["login"] = login
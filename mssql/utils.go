@@ -2,26 +2,113 @@ package mssql
 
 import (
   "fmt"
+  "github.com/hashicorp/go-cty/cty"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
   "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
   "github.com/rs/zerolog"
   "github.com/betr-io/terraform-provider-mssql/mssql/model"
+  "strings"
 )
 
+// Implemented by both *schema.ResourceData and *schema.ResourceDiff
+type changeGetter interface {
+  Id() string
+  GetChange(key string) (interface{}, interface{})
+  HasChanges(keys ...string) bool
+  GetRawConfig() cty.Value
+}
+
+// Returns the configured value of the string attribute key, which is the only way to read a write-only attribute
+func getWriteOnly(data changeGetter, key string) string {
+  config := data.GetRawConfig()
+  if config.IsNull() || !config.IsKnown() {
+    return ""
+  }
+  value := config.GetAttr(key)
+  if value.IsNull() || !value.IsKnown() {
+    return ""
+  }
+  return value.AsString()
+}
+
+// Reports whether the attribute key is set in the configuration, as opposed to computed
+func isConfigured(data changeGetter, key string) bool {
+  config := data.GetRawConfig()
+  return !config.IsNull() && config.IsKnown() && !config.GetAttr(key).IsNull()
+}
+
+// Logins are identified by their principal id, so that the ID stays the same when the login is renamed. The login name
+// is only used to import a login.
 func getLoginID(data *schema.ResourceData) string {
   host := data.Get(serverProp + ".0.host").(string)
   port := data.Get(serverProp + ".0.port").(string)
-  loginName := data.Get(loginNameProp).(string)
-  return fmt.Sprintf("sqlserver://%s:%s/%s", host, port, loginName)
+  principalID := data.Get(principalIdProp).(int)
+  return fmt.Sprintf("sqlserver://%s:%s/%d", host, port, principalID)
+}
+
+func getCredentialID(data *schema.ResourceData) string {
+  host := data.Get(serverProp + ".0.host").(string)
+  port := data.Get(serverProp + ".0.port").(string)
+  name := data.Get(credentialNameProp).(string)
+  return fmt.Sprintf("sqlserver://%s:%s/%s", host, port, name)
 }
 
+// Users are identified by their database and principal id, so that the ID stays the same when the user is renamed. The
+// user name is only used to import a user.
 func getUserID(data *schema.ResourceData) string {
   host := data.Get(serverProp + ".0.host").(string)
   port := data.Get(serverProp + ".0.port").(string)
   database := data.Get(databaseProp).(string)
-  username := data.Get(usernameProp).(string)
-  return fmt.Sprintf("sqlserver://%s:%s/%s/%s", host, port, database, username)
+  principalID := data.Get(principalIdProp).(int)
+  return fmt.Sprintf("sqlserver://%s:%s/%s/%d", host, port, database, principalID)
 }
 
 func loggerFromMeta(meta interface{}, resource, function string) zerolog.Logger {
   return meta.(model.Provider).ResourceLogger(resource, function)
 }
+
+func isDryRun(meta interface{}) bool {
+  return meta.(model.Provider).DryRun()
+}
+
+func previewSqlSchema() *schema.Schema {
+  return &schema.Schema{
+    Type:     schema.TypeList,
+    Computed: true,
+    Elem: &schema.Schema{
+      Type: schema.TypeString,
+    },
+  }
+}
+
+// Reports the statements an operation would have run when the provider is in dry run mode
+func dryRunDiagnostics(operation string, statements []string) diag.Diagnostics {
+  return diag.Diagnostics{{
+    Severity: diag.Warning,
+    Summary:  fmt.Sprintf("dry run: not executing %s", operation),
+    Detail:   strings.Join(statements, "\n"),
+  }}
+}
+
+// Reports the statements a delete would have run when the provider is in dry run mode. A delete that returns without an
+// error removes the resource from the state, so the skipped delete is reported as an error to keep it there.
+func dryRunDeleteDiagnostics(operation string, statements []string) diag.Diagnostics {
+  diags := dryRunDiagnostics(operation, statements)
+  diags[0].Severity = diag.Error
+  return diags
+}
+
+// Sets the attributes back to their values in the prior state, so that an update skipped in dry run mode leaves the
+// state as it was. Write-only attributes are never stored, so they are left out.
+func keepPriorState(data *schema.ResourceData, attributes map[string]*schema.Schema) diag.Diagnostics {
+  for key, attribute := range attributes {
+    if attribute.WriteOnly {
+      continue
+    }
+    old, _ := data.GetChange(key)
+    if err := data.Set(key, old); err != nil {
+      return diag.FromErr(err)
+    }
+  }
+  return nil
+}
@@ -0,0 +1,170 @@
+package mssql
+
+import (
+  "context"
+  "fmt"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+  "github.com/pkg/errors"
+  "github.com/rs/zerolog"
+  "github.com/betr-io/terraform-provider-mssql/sql"
+  "strings"
+)
+
+// The names on the server that the values of the attribute key are checked against
+type nameLookup struct {
+  key    string
+  kind   string
+  values []string
+  names  func(ctx context.Context) ([]string, error)
+}
+
+// An error looking up the names of the attribute key on the server, which leaves its values unchecked
+type nameLookupError struct {
+  key string
+  err error
+}
+
+func (e *nameLookupError) Error() string {
+  return fmt.Sprintf("%s: unable to look up the names on the server: %s", e.key, e.err)
+}
+
+func (e *nameLookupError) Unwrap() error {
+  return e.err
+}
+
+// Reports whether the server block of the resource is known at plan time, so that names can be looked up on the server
+func isServerKnown(diff *schema.ResourceDiff) bool {
+  config := diff.GetRawConfig()
+  return !config.IsNull() && config.GetAttr(serverProp).IsWhollyKnown()
+}
+
+// Returns the known values of key that are to be checked at plan time, which are the values that change
+func namesToValidate(diff *schema.ResourceDiff, key string) []string {
+  if !diff.HasChange(key) || !diff.NewValueKnown(key) {
+    return nil
+  }
+  return namesOf(diff.Get(key))
+}
+
+// Returns the values of key that are checked again on apply, which are the values that change
+func namesToCheck(data *schema.ResourceData, key string) []string {
+  if !data.HasChange(key) {
+    return nil
+  }
+  return namesOf(data.Get(key))
+}
+
+func namesOf(value interface{}) []string {
+  switch value := value.(type) {
+  case string:
+    return []string{value}
+  case *schema.Set:
+    return toStringSlice(value.List())
+  }
+  return nil
+}
+
+// Checks the values of each lookup against the names on the server. A lookup that fails returns a *nameLookupError.
+func checkNames(ctx context.Context, lookups []nameLookup) error {
+  for _, lookup := range lookups {
+    if len(lookup.values) == 0 {
+      continue
+    }
+    names, err := lookup.names(ctx)
+    if err != nil {
+      return &nameLookupError{key: lookup.key, err: err}
+    }
+    if err = validateNames(lookup.key, lookup.kind, lookup.values, names); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+// Checks the values of each lookup at plan time. An unreachable server skips the check; any other failed lookup is
+// logged, and reported as a warning by the apply, as a plan cannot carry warnings.
+func validateNamesAtPlan(ctx context.Context, logger zerolog.Logger, lookups []nameLookup) error {
+  err := checkNames(ctx, lookups)
+  var lookupErr *nameLookupError
+  if !errors.As(err, &lookupErr) {
+    return err
+  }
+  if errors.Is(err, sql.ErrServerUnreachable) {
+    logger.Info().Err(err).Msg("server not reachable, skipped validation of names")
+  } else {
+    logger.Warn().Err(err).Msg("skipped validation of names")
+  }
+  return nil
+}
+
+// Checks the values of each lookup again on apply, and returns a warning if one does not exist or cannot be checked.
+// The change is applied regardless, and fails if the server rejects the name.
+func nameWarnings(ctx context.Context, lookups []nameLookup) diag.Diagnostics {
+  if err := checkNames(ctx, lookups); err != nil {
+    return diag.Diagnostics{{Severity: diag.Warning, Summary: err.Error()}}
+  }
+  return nil
+}
+
+// Checks that each of values is one of names, ignoring case as SQL Server does with the default collation. An unknown
+// value is reported with the most similar name, if any. No names means the server does not apply the attribute.
+func validateNames(key, kind string, values, names []string) error {
+  if len(names) == 0 {
+    return nil
+  }
+  for _, value := range values {
+    if value == "" || containsFold(names, value) {
+      continue
+    }
+    if suggestion := closestName(value, names); suggestion != "" {
+      return errors.Errorf("%s: %s [%s] does not exist, did you mean [%s]?", key, kind, value, suggestion)
+    }
+    return errors.Errorf("%s: %s [%s] does not exist", key, kind, value)
+  }
+  return nil
+}
+
+func containsFold(names []string, value string) bool {
+  for _, name := range names {
+    if strings.EqualFold(name, value) {
+      return true
+    }
+  }
+  return false
+}
+
+// Returns the name closest to value by edit distance, if it is close enough to be a likely typo
+func closestName(value string, names []string) string {
+  best, bestDistance := "", len(value)/3+1
+  for _, name := range names {
+    if distance := editDistance(strings.ToLower(value), strings.ToLower(name)); distance <= bestDistance {
+      if distance < bestDistance || best == "" {
+        best, bestDistance = name, distance
+      }
+    }
+  }
+  return best
+}
+
+// Levenshtein distance between a and b
+func editDistance(a, b string) int {
+  ra, rb := []rune(a), []rune(b)
+  previous := make([]int, len(rb)+1)
+  for j := range previous {
+    previous[j] = j
+  }
+  for i := 1; i <= len(ra); i++ {
+    current := make([]int, len(rb)+1)
+    current[0] = i
+    for j := 1; j <= len(rb); j++ {
+      cost := 1
+      if ra[i-1] == rb[j-1] {
+        cost = 0
+      }
+      current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+    }
+    previous = current
+  }
+  return previous[len(rb)]
+}
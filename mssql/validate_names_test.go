@@ -0,0 +1,87 @@
+package mssql
+
+import (
+  "context"
+  "fmt"
+  "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+  "github.com/pkg/errors"
+  "github.com/rs/zerolog"
+  "github.com/betr-io/terraform-provider-mssql/sql"
+  "strings"
+  "testing"
+)
+
+func TestValidateNames(t *testing.T) {
+  databases := []string{"master", "tempdb", "model", "msdb", "sales"}
+
+  if err := validateNames(defaultDatabaseProp, "database", []string{"TempDB", ""}, databases); err != nil {
+    t.Errorf("expected names to match ignoring case, got %s", err)
+  }
+  if err := validateNames(defaultDatabaseProp, "database", []string{"unknown"}, nil); err != nil {
+    t.Errorf("expected no names to skip validation, got %s", err)
+  }
+
+  err := validateNames(defaultDatabaseProp, "database", []string{"sale"}, databases)
+  if err == nil {
+    t.Fatal("expected error for unknown database")
+  }
+  if expected := "default_database: database [sale] does not exist, did you mean [sales]?"; err.Error() != expected {
+    t.Errorf("expected %q, got %q", expected, err.Error())
+  }
+
+  err = validateNames(defaultDatabaseProp, "database", []string{"inventory"}, databases)
+  if err == nil {
+    t.Fatal("expected error for unknown database")
+  }
+  if strings.Contains(err.Error(), "did you mean") {
+    t.Errorf("expected no suggestion, got %q", err.Error())
+  }
+}
+
+func TestEditDistance(t *testing.T) {
+  for _, c := range []struct {
+    a, b     string
+    distance int
+  }{
+    {"", "", 0},
+    {"db_owner", "db_owner", 0},
+    {"db_ownr", "db_owner", 1},
+    {"russain", "russian", 2},
+    {"kitten", "sitting", 3},
+    {"", "dbo", 3},
+  } {
+    if distance := editDistance(c.a, c.b); distance != c.distance {
+      t.Errorf("expected distance %d between %q and %q, got %d", c.distance, c.a, c.b, distance)
+    }
+  }
+}
+
+func TestCheckNames(t *testing.T) {
+  databases := func(ctx context.Context) ([]string, error) {
+    return []string{"master", "sales"}, nil
+  }
+  unreachable := func(ctx context.Context) ([]string, error) {
+    return nil, fmt.Errorf("%w: dial tcp: connection refused", sql.ErrServerUnreachable)
+  }
+
+  if err := checkNames(context.Background(), []nameLookup{{key: defaultDatabaseProp, kind: "database", values: []string{"Sales"}, names: databases}}); err != nil {
+    t.Errorf("expected names to match, got %s", err)
+  }
+  if err := checkNames(context.Background(), []nameLookup{{key: defaultLanguageProp, kind: "language", names: unreachable}}); err != nil {
+    t.Errorf("expected no values to skip the lookup, got %s", err)
+  }
+
+  err := checkNames(context.Background(), []nameLookup{{key: defaultLanguageProp, kind: "language", values: []string{"english"}, names: unreachable}})
+  var lookupErr *nameLookupError
+  if !errors.As(err, &lookupErr) || !errors.Is(err, sql.ErrServerUnreachable) {
+    t.Fatalf("expected lookup error of unreachable server, got %v", err)
+  }
+  if err := validateNamesAtPlan(context.Background(), zerolog.Nop(), []nameLookup{{key: defaultLanguageProp, kind: "language", values: []string{"english"}, names: unreachable}}); err != nil {
+    t.Errorf("expected unreachable server to skip validation, got %s", err)
+  }
+
+  diags := nameWarnings(context.Background(), []nameLookup{{key: defaultDatabaseProp, kind: "database", values: []string{"sale"}, names: databases}})
+  if len(diags) != 1 || diags[0].Severity != diag.Warning {
+    t.Errorf("expected a warning for unknown database, got %v", diags)
+  }
+}
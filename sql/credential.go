@@ -0,0 +1,89 @@
+package sql
+
+import (
+  "context"
+  "database/sql"
+  "github.com/betr-io/terraform-provider-mssql/mssql/model"
+)
+
+func (c *Connector) GetCredential(ctx context.Context, name string) (*model.Credential, error) {
+  cmd := `SELECT credential_id, name, credential_identity FROM [master].[sys].[credentials] WHERE [name] = @name`
+  var credential model.Credential
+  err := c.QueryRowContext(ctx, cmd,
+    func(r *sql.Row) error {
+      return r.Scan(&credential.CredentialID, &credential.Name, &credential.Identity)
+    },
+    sql.Named("name", name),
+  )
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return nil, nil
+    }
+    return nil, err
+  }
+  return &credential, nil
+}
+
+// Secrets such as shared access signatures are longer than the 128 characters QuoteName accepts, so they are quoted
+// with REPLACE
+func (c *Connector) CreateCredential(ctx context.Context, credential *model.Credential) error {
+  cmd := `DECLARE @sql nvarchar(max)
+          SET @sql = 'CREATE CREDENTIAL ' + QuoteName(@name) + ' WITH IDENTITY = ''' + REPLACE(@identity, '''', '''''') + ''''
+          IF NOT @secret = ''
+            BEGIN
+              SET @sql = @sql + ', SECRET = ''' + REPLACE(@secret, '''', '''''') + ''''
+            END
+          EXEC (@sql)`
+  return c.ExecContext(ctx, cmd,
+    sql.Named("name", credential.Name),
+    sql.Named("identity", credential.Identity),
+    sql.Named("secret", credential.Secret))
+}
+
+// ALTER CREDENTIAL resets both the identity and the secret, so the secret is always set again
+func (c *Connector) UpdateCredential(ctx context.Context, credential *model.Credential) error {
+  cmd := `DECLARE @sql nvarchar(max)
+          SET @sql = 'ALTER CREDENTIAL ' + QuoteName(@name) + ' WITH IDENTITY = ''' + REPLACE(@identity, '''', '''''') + ''''
+          IF NOT @secret = ''
+            BEGIN
+              SET @sql = @sql + ', SECRET = ''' + REPLACE(@secret, '''', '''''') + ''''
+            END
+          EXEC (@sql)`
+  return c.ExecContext(ctx, cmd,
+    sql.Named("name", credential.Name),
+    sql.Named("identity", credential.Identity),
+    sql.Named("secret", credential.Secret))
+}
+
+func (c *Connector) DeleteCredential(ctx context.Context, name string) error {
+  cmd := `DECLARE @sql nvarchar(max)
+          IF EXISTS (SELECT 1 FROM [master].[sys].[credentials] WHERE [name] = @name)
+            BEGIN
+              SET @sql = 'DROP CREDENTIAL ' + QuoteName(@name)
+              EXEC (@sql)
+            END`
+  return c.ExecContext(ctx, cmd, sql.Named("name", name))
+}
+
+// Returns the T-SQL CreateCredential runs for the credential, with the secret masked
+func CreateCredentialStatements(credential *model.Credential) []string {
+  return []string{"CREATE CREDENTIAL " + quoteName(credential.Name, '[') + credentialOptions(credential)}
+}
+
+// Returns the T-SQL UpdateCredential runs for the credential, with the secret masked
+func UpdateCredentialStatements(credential *model.Credential) []string {
+  return []string{"ALTER CREDENTIAL " + quoteName(credential.Name, '[') + credentialOptions(credential)}
+}
+
+// Returns the T-SQL DeleteCredential runs for the credential
+func DeleteCredentialStatements(name string) []string {
+  return []string{"DROP CREDENTIAL " + quoteName(name, '[')}
+}
+
+func credentialOptions(credential *model.Credential) string {
+  options := " WITH IDENTITY = " + quoteName(credential.Identity, '\'')
+  if credential.Secret != "" {
+    options += ", SECRET = " + maskedPassword
+  }
+  return options
+}
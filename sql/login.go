@@ -3,17 +3,50 @@ package sql
 import (
   "context"
   "database/sql"
+  "fmt"
   "github.com/betr-io/terraform-provider-mssql/mssql/model"
+  "github.com/pkg/errors"
+  "strings"
+  "time"
 )
 
 func (c *Connector) GetLogin(ctx context.Context, name string) (*model.Login, error) {
-  var login model.Login
-  err := c.QueryRowContext(ctx,
-    "SELECT principal_id, name, CONVERT(VARCHAR(1000), [sid], 1), default_database_name, default_language_name FROM [master].[sys].[sql_logins] WHERE [name] = @name",
+  return c.getLogin(ctx, "[name] = @name", sql.Named("name", name))
+}
+
+// Looks up a login by its principal id, which unlike its name is kept when the login is renamed
+func (c *Connector) GetLoginByPrincipalID(ctx context.Context, principalID int64) (*model.Login, error) {
+  return c.getLogin(ctx, "principal_id = @principalId", sql.Named("principalId", principalID))
+}
+
+func (c *Connector) getLogin(ctx context.Context, condition string, arg sql.NamedArg) (*model.Login, error) {
+  cmd := `SELECT principal_id, name, CONVERT(VARCHAR(1000), [sid], 1), COALESCE(default_database_name, ''), COALESCE(default_language_name, ''),
+                 CASE type WHEN 'U' THEN 'windows_user' WHEN 'G' THEN 'windows_group' WHEN 'E' THEN 'external_user' WHEN 'X' THEN 'external_group' WHEN 'C' THEN 'certificate' WHEN 'K' THEN 'asymmetric_key' ELSE 'sql' END,
+                 CASE WHEN type IN ('E', 'X') THEN LOWER(CONVERT(VARCHAR(36), CAST(SUBSTRING([sid], 1, 16) AS UNIQUEIDENTIFIER))) ELSE '' END,
+                 COALESCE((SELECT name FROM [master].[sys].[certificates] WHERE [sid] = sp.[sid]), ''),
+                 COALESCE((SELECT name FROM [master].[sys].[asymmetric_keys] WHERE [sid] = sp.[sid]), ''),
+                 COALESCE((SELECT is_policy_checked FROM [master].[sys].[sql_logins] WHERE principal_id = sp.principal_id), CAST(0 AS bit)),
+                 COALESCE((SELECT is_expiration_checked FROM [master].[sys].[sql_logins] WHERE principal_id = sp.principal_id), CAST(0 AS bit)),
+                 COALESCE((SELECT CONVERT(VARCHAR(MAX), password_hash, 1) FROM [master].[sys].[sql_logins] WHERE principal_id = sp.principal_id), ''),
+                 CAST(IIF(is_disabled = 1, 0, 1) AS bit),
+                 CASE WHEN EXISTS (SELECT 1 FROM [master].[sys].[server_permissions] WHERE grantee_principal_id = sp.principal_id AND type = 'COSQ' AND state = 'D') THEN CAST(1 AS bit) ELSE CAST(0 AS bit) END,
+                 COALESCE((SELECT STRING_AGG(r.name, ',') FROM [master].[sys].[server_role_members] srm INNER JOIN [master].[sys].[server_principals] r ON srm.role_principal_id = r.principal_id WHERE srm.member_principal_id = sp.principal_id), ''),
+                 COALESCE(CAST(LOGINPROPERTY(name, 'IsLocked') AS bit), CAST(0 AS bit)),
+                 COALESCE(CAST(LOGINPROPERTY(name, 'IsExpired') AS bit), CAST(0 AS bit)),
+                 COALESCE(CAST(LOGINPROPERTY(name, 'BadPasswordCount') AS int), 0),
+                 COALESCE(CONVERT(VARCHAR(33), CAST(LOGINPROPERTY(name, 'PasswordLastSetTime') AS datetime), 126), ''),
+                 COALESCE(CAST(LOGINPROPERTY(name, 'DaysUntilExpiration') AS int), -1)
+          FROM [master].[sys].[server_principals] sp
+          WHERE ` + condition + ` AND type IN ('S', 'U', 'G', 'E', 'X', 'C', 'K')`
+  var (
+    login       model.Login
+    serverRoles string
+  )
+  err := c.QueryRowContext(ctx, cmd,
     func(r *sql.Row) error {
-      return r.Scan(&login.PrincipalID, &login.LoginName, &login.SIDStr, &login.DefaultDatabase, &login.DefaultLanguage)
+      return r.Scan(&login.PrincipalID, &login.LoginName, &login.SIDStr, &login.DefaultDatabase, &login.DefaultLanguage, &login.Type, &login.ObjectId, &login.CertificateName, &login.AsymmetricKeyName, &login.CheckPolicy, &login.CheckExpiration, &login.PasswordHash, &login.Enabled, &login.DenyConnectSql, &serverRoles, &login.IsLocked, &login.IsExpired, &login.BadPasswordCount, &login.PasswordLastSetTime, &login.DaysUntilExpiration)
     },
-    sql.Named("name", name),
+    arg,
   )
   if err != nil {
     if err == sql.ErrNoRows {
@@ -21,77 +54,271 @@ func (c *Connector) GetLogin(ctx context.Context, name string) (*model.Login, er
     }
     return nil, err
   }
+  if serverRoles == "" {
+    login.ServerRoles = make([]string, 0)
+  } else {
+    login.ServerRoles = strings.Split(serverRoles, ",")
+  }
+  if login.Credentials, err = c.getLoginCredentials(ctx, login.PrincipalID); err != nil {
+    return nil, err
+  }
   return &login, nil
 }
 
-func (c *Connector) CreateLogin(ctx context.Context, name, password, sid, defaultDatabase, defaultLanguage string) error {
+// Returns the names of the credentials mapped to the login. Azure SQL Database has no server credentials, and the
+// catalog views are only referenced in dynamic SQL so that the query compiles there.
+func (c *Connector) getLoginCredentials(ctx context.Context, principalID int64) ([]string, error) {
+  cmd := `IF @@VERSION NOT LIKE 'Microsoft SQL Azure%'
+            EXEC sp_executesql N'SELECT c.name FROM [master].[sys].[server_principal_credentials] spc INNER JOIN [master].[sys].[credentials] c ON spc.credential_id = c.credential_id WHERE spc.principal_id = @principalId',
+                               N'@principalId int', @principalId`
+  credentials, err := c.queryNames(ctx, cmd, sql.Named("principalId", principalID))
+  if credentials == nil {
+    credentials = make([]string, 0)
+  }
+  return credentials, err
+}
+
+// Reports whether password is the current password of the SQL Server authentication login. Reports true if there is no
+// such login, or if its password hash cannot be read because the provider login lacks CONTROL SERVER.
+func (c *Connector) LoginPasswordMatches(ctx context.Context, name, password string) (bool, error) {
+  cmd := `SELECT COALESCE(PWDCOMPARE(@password, password_hash), 1) FROM [master].[sys].[sql_logins] WHERE [name] = @name`
+  var matches int
+  err := c.QueryRowContext(ctx, cmd,
+    func(r *sql.Row) error {
+      return r.Scan(&matches)
+    },
+    sql.Named("name", name),
+    sql.Named("password", password),
+  )
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return true, nil
+    }
+    return false, err
+  }
+  return matches == 1, nil
+}
+
+// Returns the names of the databases on the server, or none on Azure SQL Database, where logins have no default database
+func (c *Connector) GetDatabaseNames(ctx context.Context) ([]string, error) {
+  cmd := `IF @@VERSION NOT LIKE 'Microsoft SQL Azure%'
+            SELECT name FROM [master].[sys].[databases]`
+  return c.queryNames(ctx, cmd)
+}
+
+// Returns the names and aliases of the languages of the server, or none on Azure SQL Database, where logins have no
+// default language
+func (c *Connector) GetLanguageNames(ctx context.Context) ([]string, error) {
+  cmd := `IF @@VERSION NOT LIKE 'Microsoft SQL Azure%'
+            SELECT name FROM [master].[sys].[syslanguages] UNION SELECT alias FROM [master].[sys].[syslanguages]`
+  return c.queryNames(ctx, cmd)
+}
+
+func (c *Connector) CreateLogin(ctx context.Context, login *model.Login) error {
+  database := "master"
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return err
+  }
+  statements, err := createLoginStatements(login, server, false)
+  if err != nil {
+    return err
+  }
+  return c.execStatements(ctx, statements)
+}
+
+// Brings the login on the server to login. Only the options that differ from the login as read from the server are
+// changed, with the same statements UpdateLoginStatements previews.
+func (c *Connector) UpdateLogin(ctx context.Context, login *model.Login) error {
+  database := "master"
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return err
+  }
+  current, err := c.GetLogin(ctx, login.LoginName)
+  if err != nil {
+    return err
+  }
+  if current == nil {
+    return errors.Errorf("login [%s] not found", login.LoginName)
+  }
+  statements, err := updateLoginStatements(current, login, server, false)
+  if err != nil {
+    return err
+  }
+  return c.execStatements(ctx, statements)
+}
+
+func (c *Connector) RenameLogin(ctx context.Context, name, newName string) error {
+  return c.ExecContext(ctx, renameLoginStatement(name, newName))
+}
+
+// Drops the login. Sessions of the login are killed, waited for or reported as an error depending on deleteSessions,
+// which is one of kill, wait or fail. Unless force is set, the login is not dropped if it owns databases, endpoints or
+// agent jobs; if force is set, their ownership is transferred to the sa login. Ownership is only transferred right before
+// the DROP, once the sessions no longer stand in its way.
+func (c *Connector) DeleteLogin(ctx context.Context, name, deleteSessions string, force bool) error {
+  if !force {
+    owned, err := c.getOwnedByLogin(ctx, name)
+    if err != nil {
+      return err
+    }
+    if len(owned) > 0 {
+      return errors.Errorf("login [%s] owns %s; transfer their ownership, or set force_destroy to transfer it to the sa login", name, strings.Join(owned, ", "))
+    }
+  }
+
+  var err error
+  switch deleteSessions {
+  case "wait":
+    err = c.waitForSessionsOfLogin(ctx, name)
+  case "fail":
+    var sessions []string
+    if sessions, err = c.getSessionsOfLogin(ctx, name); err == nil && len(sessions) > 0 {
+      err = errors.Errorf("login [%s] has active sessions: %s", name, strings.Join(sessions, ", "))
+    }
+  default:
+    err = c.killSessionsForLogin(ctx, name)
+  }
+  if err != nil {
+    return err
+  }
+
+  if force {
+    if err = c.transferOwnershipOfLogin(ctx, name); err != nil {
+      return err
+    }
+  }
+
   cmd := `DECLARE @sql nvarchar(max)
-          SET @sql = 'CREATE LOGIN ' + QuoteName(@name) + ' ' +
-                     'WITH PASSWORD = ' + QuoteName(@password, '''')
-          IF NOT @sid = ''
-            BEGIN
-              SET @sql = @sql + ', SID = ' + CONVERT(VARCHAR(1000), @sid, 1)
-            END
+          SET @sql = 'IF EXISTS (SELECT 1 FROM [master].[sys].[server_principals] WHERE [name] = ' + QuoteName(@name, '''') + ') ' +
+                     'DROP LOGIN ' + QuoteName(@name)
+          EXEC (@sql)`
+  return c.ExecContext(ctx, cmd, sql.Named("name", name))
+}
+
+// Returns the databases, endpoints and agent jobs owned by the login
+func (c *Connector) getOwnedByLogin(ctx context.Context, name string) ([]string, error) {
+  cmd := `DECLARE @owned TABLE (owned_object nvarchar(max))
+          DECLARE @sid varbinary(85) = SUSER_SID(@name)
+          INSERT INTO @owned SELECT 'database ' + QuoteName(name) FROM [sys].[databases] WHERE owner_sid = @sid
           IF @@VERSION NOT LIKE 'Microsoft SQL Azure%'
             BEGIN
-              IF @defaultDatabase = '' SET @defaultDatabase = 'master'
-              IF NOT @defaultDatabase = 'master'
-                BEGIN
-                  SET @sql = @sql + ', DEFAULT_DATABASE = ' + QuoteName(@defaultDatabase)
-                END
-              DECLARE @serverLanguage nvarchar(max) = (SELECT lang.name FROM [sys].[configurations] c INNER JOIN [sys].[syslanguages] lang ON c.[value] = lang.langid WHERE c.name = 'default language')
-              IF NOT @defaultLanguage IN ('', @serverLanguage)
-                BEGIN
-                  SET @sql = @sql + ', DEFAULT_LANGUAGE = ' + QuoteName(@defaultLanguage)
-                END
+              INSERT INTO @owned EXEC sp_executesql N'SELECT ''endpoint '' + QuoteName(name) FROM [sys].[endpoints] WHERE principal_id = SUSER_ID(@name)', N'@name nvarchar(128)', @name
+              IF DB_ID('msdb') IS NOT NULL
+                INSERT INTO @owned EXEC sp_executesql N'SELECT ''agent job '' + QuoteName(name) FROM [msdb].[dbo].[sysjobs] WHERE owner_sid = @sid', N'@sid varbinary(85)', @sid
             END
-          EXEC (@sql)`
-  database := "master"
-  return c.
-    setDatabase(&database).
-    ExecContext(ctx, cmd,
+          SELECT owned_object FROM @owned`
+  owned := make([]string, 0)
+  err := c.QueryContext(ctx, cmd,
+    func(r *sql.Rows) error {
+      for r.Next() {
+        var object string
+        if err := r.Scan(&object); err != nil {
+          return err
+        }
+        owned = append(owned, object)
+      }
+      return r.Err()
+    },
     sql.Named("name", name),
-    sql.Named("password", password),
-    sql.Named("sid", sid),
-    sql.Named("defaultDatabase", defaultDatabase),
-    sql.Named("defaultLanguage", defaultLanguage))
+  )
+  return owned, err
 }
 
-func (c *Connector) UpdateLogin(ctx context.Context, name, password, defaultDatabase, defaultLanguage string) error {
-  cmd := `DECLARE @sql nvarchar(max)
-          SET @sql = 'ALTER LOGIN ' + QuoteName(@name) + ' ' +
-                     'WITH PASSWORD = ' + QuoteName(@password, '''')
+// Transfers the ownership of the databases, endpoints and agent jobs owned by the login to the sa login. Fails if the
+// login owns any of them and the server has no sa login, as is the case on Azure SQL Database.
+func (c *Connector) transferOwnershipOfLogin(ctx context.Context, name string) error {
+  var owner string
+  err := c.QueryRowContext(ctx, "SELECT COALESCE(SUSER_SNAME(0x01), '')",
+    func(r *sql.Row) error {
+      return r.Scan(&owner)
+    },
+  )
+  if err != nil {
+    return err
+  }
+  if owner == "" {
+    owned, err := c.getOwnedByLogin(ctx, name)
+    if err != nil {
+      return err
+    }
+    if len(owned) > 0 {
+      return errors.Errorf("login [%s] owns %s, and the server has no sa login to transfer their ownership to; transfer their ownership before dropping the login", name, strings.Join(owned, ", "))
+    }
+    return nil
+  }
+
+  cmd := `DECLARE @sid varbinary(85) = SUSER_SID(@name)
+          DECLARE @sql nvarchar(max)
+          DECLARE @statements TABLE (statement nvarchar(max))
+          INSERT INTO @statements SELECT 'ALTER AUTHORIZATION ON DATABASE::' + QuoteName(name) + ' TO ' + QuoteName(@owner) FROM [sys].[databases] WHERE owner_sid = @sid
           IF @@VERSION NOT LIKE 'Microsoft SQL Azure%'
             BEGIN
-              IF @defaultDatabase = '' SET @defaultDatabase = 'master'
-              IF NOT @defaultDatabase IN (SELECT default_database_name FROM [master].[sys].[sql_logins] WHERE [name] = @name)
-                BEGIN
-                  SET @sql = @sql + ', DEFAULT_DATABASE = ' + QuoteName(@defaultDatabase)
-                END
-                DECLARE @language nvarchar(max) = @defaultLanguage
-              IF @language = '' SET @language = (SELECT lang.name FROM [sys].[configurations] c INNER JOIN [sys].[syslanguages] lang ON c.[value] = lang.langid WHERE c.name = 'default language')
-              IF @language != (SELECT default_language_name FROM [master].[sys].[sql_logins] WHERE [name] = @name)
-                BEGIN
-                  SET @sql = @sql + ', DEFAULT_LANGUAGE = ' + QuoteName(@language)
-                END
-              END
-          EXEC (@sql)`
+              INSERT INTO @statements EXEC sp_executesql N'SELECT ''ALTER AUTHORIZATION ON ENDPOINT::'' + QuoteName(name) + '' TO '' + QuoteName(@owner) FROM [sys].[endpoints] WHERE principal_id = SUSER_ID(@name)', N'@name nvarchar(128), @owner nvarchar(128)', @name, @owner
+              IF DB_ID('msdb') IS NOT NULL
+                INSERT INTO @statements EXEC sp_executesql N'SELECT ''EXEC [msdb].[dbo].[sp_update_job] @job_id = '''''' + CONVERT(nvarchar(36), job_id) + '''''', @owner_login_name = '' + QuoteName(@owner, '''''''') FROM [msdb].[dbo].[sysjobs] WHERE owner_sid = @sid', N'@sid varbinary(85), @owner nvarchar(128)', @sid, @owner
+            END
+          DECLARE statementsToRun CURSOR FAST_FORWARD FOR SELECT statement FROM @statements
+          OPEN statementsToRun
+          FETCH NEXT FROM statementsToRun INTO @sql
+          WHILE @@FETCH_STATUS = 0
+          BEGIN
+            EXEC (@sql)
+            FETCH NEXT FROM statementsToRun INTO @sql
+          END
+          CLOSE statementsToRun
+          DEALLOCATE statementsToRun`
   return c.ExecContext(ctx, cmd,
     sql.Named("name", name),
-    sql.Named("password", password),
-    sql.Named("defaultDatabase", defaultDatabase),
-    sql.Named("defaultLanguage", defaultLanguage))
+    sql.Named("owner", owner))
 }
 
-func (c *Connector) DeleteLogin(ctx context.Context, name string) error {
-  if err := c.killSessionsForLogin(ctx, name); err != nil {
-    return err
+// Returns a description of each session of the login, with its host and program
+func (c *Connector) getSessionsOfLogin(ctx context.Context, name string) ([]string, error) {
+  cmd := `SELECT session_id, COALESCE(host_name, ''), COALESCE(program_name, '')
+          FROM sys.dm_exec_sessions
+          WHERE login_name = @name`
+  sessions := make([]string, 0)
+  err := c.QueryContext(ctx, cmd,
+    func(r *sql.Rows) error {
+      for r.Next() {
+        var (
+          sessionId int
+          host      string
+          program   string
+        )
+        if err := r.Scan(&sessionId, &host, &program); err != nil {
+          return err
+        }
+        sessions = append(sessions, fmt.Sprintf("session %d from host [%s] running [%s]", sessionId, host, program))
+      }
+      return r.Err()
+    },
+    sql.Named("name", name),
+  )
+  return sessions, err
+}
+
+// Polls the sessions of the login until there are none, or ctx is done
+func (c *Connector) waitForSessionsOfLogin(ctx context.Context, name string) error {
+  ticker := time.NewTicker(time.Second)
+  defer ticker.Stop()
+
+  for {
+    sessions, err := c.getSessionsOfLogin(ctx, name)
+    if err != nil {
+      return err
+    }
+    if len(sessions) == 0 {
+      return nil
+    }
+    select {
+    case <-ctx.Done():
+      return errors.Errorf("timed out waiting for the sessions of login [%s] to end: %s", name, strings.Join(sessions, ", "))
+    case <-ticker.C:
+    }
   }
-  cmd := `DECLARE @sql nvarchar(max)
-          SET @sql = 'IF EXISTS (SELECT 1 FROM [master].[sys].[sql_logins] WHERE [name] = ' + QuoteName(@name, '''') + ') ' +
-                     'DROP LOGIN ' + QuoteName(@name)
-          EXEC (@sql)`
-  return c.ExecContext(ctx, cmd, sql.Named("name", name))
 }
 
 func (c *Connector) killSessionsForLogin(ctx context.Context, name string) error {
@@ -115,3 +342,278 @@ func (c *Connector) killSessionsForLogin(ctx context.Context, name string) error
           DEALLOCATE sessionsToKill`
   return c.ExecContext(ctx, cmd, sql.Named("name", name))
 }
+
+// Returns the T-SQL CreateLogin runs for the login, with the password masked
+func (c *Connector) CreateLoginStatements(ctx context.Context, login *model.Login) ([]string, error) {
+  database := "master"
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return createLoginStatements(login, server, true)
+}
+
+// Returns the T-SQL RenameLogin and UpdateLogin run to bring the login from old to new, with the password masked. The
+// statements are built against the login as read from the server, or against old if it is not found.
+func (c *Connector) UpdateLoginStatements(ctx context.Context, old, new *model.Login) ([]string, error) {
+  database := "master"
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return nil, err
+  }
+  current, err := c.GetLogin(ctx, old.LoginName)
+  if err != nil {
+    return nil, err
+  }
+  if current == nil {
+    current = old
+  }
+  statements := make([]string, 0)
+  if new.LoginName != old.LoginName {
+    statements = append(statements, renameLoginStatement(old.LoginName, new.LoginName))
+  }
+  renamed := *current
+  renamed.LoginName = new.LoginName
+  update, err := updateLoginStatements(&renamed, new, server, true)
+  if err != nil {
+    return nil, err
+  }
+  return append(statements, update...), nil
+}
+
+// Returns the statements that create the login on the server, with the password and password hash masked if mask is
+// set. The CREATE LOGIN is the first statement, and each statement is run on its own, as a failed statement does not end
+// a batch and the statements that follow must never touch a login that already exists.
+func createLoginStatements(login *model.Login, server *serverInfo, mask bool) ([]string, error) {
+  create, err := createLoginStatement(login, server, mask)
+  if err != nil {
+    return nil, err
+  }
+  // A new login is enabled, may connect, and is a member of no server role
+  created := &model.Login{LoginName: login.LoginName, Enabled: true}
+  return append([]string{create}, loginStateStatements(created, login, server)...), nil
+}
+
+// Returns the CREATE LOGIN statement for the login on the server, with the password and password hash masked if mask
+// is set
+func createLoginStatement(login *model.Login, server *serverInfo, mask bool) (string, error) {
+  stmt := "CREATE LOGIN " + quoteName(login.LoginName, '[')
+  var options []string
+  switch login.Type {
+  case "sql":
+    password, err := passwordValue(login, mask)
+    if err != nil {
+      return "", err
+    }
+    if login.MustChangePassword && !server.azure {
+      password += " MUST_CHANGE"
+    }
+    options = append(options, "PASSWORD = "+password)
+    if login.SIDStr != "" {
+      sid, err := binaryLiteral("sid", login.SIDStr)
+      if err != nil {
+        return "", err
+      }
+      options = append(options, "SID = "+sid)
+    }
+  case "external_user", "external_group":
+    stmt += " FROM EXTERNAL PROVIDER"
+    if login.ObjectId != "" {
+      options = append(options, "OBJECT_ID = "+quoteName(login.ObjectId, '\''))
+    }
+  // Certificate and asymmetric key mapped logins have no default database or language
+  case "certificate":
+    return stmt + " FROM CERTIFICATE " + quoteName(login.CertificateName, '['), nil
+  case "asymmetric_key":
+    return stmt + " FROM ASYMMETRIC KEY " + quoteName(login.AsymmetricKeyName, '['), nil
+  default:
+    stmt += " FROM WINDOWS"
+  }
+  // Logins on Azure SQL Database have no default database, language or password policy
+  if !server.azure {
+    if login.DefaultDatabase != "" && !strings.EqualFold(login.DefaultDatabase, "master") {
+      options = append(options, "DEFAULT_DATABASE = "+quoteName(login.DefaultDatabase, '['))
+    }
+    if login.DefaultLanguage != "" && !strings.EqualFold(login.DefaultLanguage, server.defaultLanguage) {
+      options = append(options, "DEFAULT_LANGUAGE = "+quoteName(login.DefaultLanguage, '['))
+    }
+    if login.Type == "sql" {
+      options = append(options, "CHECK_POLICY = "+onOff(login.CheckPolicy), "CHECK_EXPIRATION = "+onOff(login.CheckExpiration))
+    }
+  }
+  return withOptions(stmt, options), nil
+}
+
+// Returns the statements that bring current, the login as read from the server, to login, with the password and
+// password hash masked if mask is set
+func updateLoginStatements(current, login *model.Login, server *serverInfo, mask bool) ([]string, error) {
+  var statements []string
+  alter, err := alterLoginStatement(current, login, server, mask)
+  if err != nil {
+    return nil, err
+  }
+  if alter != "" {
+    statements = append(statements, alter)
+  }
+  return append(statements, loginStateStatements(current, login, server)...), nil
+}
+
+// Returns the ALTER LOGIN statement that sets the password of the login and the options that differ from current, or
+// "" if there are none
+func alterLoginStatement(current, login *model.Login, server *serverInfo, mask bool) (string, error) {
+  var options []string
+  if login.Type == "sql" {
+    password, err := passwordOption(current, login, server, mask)
+    if err != nil {
+      return "", err
+    }
+    if password != "" {
+      options = append(options, password)
+    }
+  }
+  // Logins on Azure SQL Database, and certificate and asymmetric key mapped logins, have no default database or language
+  if !server.azure && login.Type != "certificate" && login.Type != "asymmetric_key" {
+    database := login.DefaultDatabase
+    if database == "" {
+      database = "master"
+    }
+    if !strings.EqualFold(database, current.DefaultDatabase) {
+      options = append(options, "DEFAULT_DATABASE = "+quoteName(database, '['))
+    }
+    language := login.DefaultLanguage
+    if language == "" {
+      language = server.defaultLanguage
+    }
+    if !strings.EqualFold(language, current.DefaultLanguage) {
+      options = append(options, "DEFAULT_LANGUAGE = "+quoteName(language, '['))
+    }
+    if login.Type == "sql" && (login.CheckPolicy != current.CheckPolicy || login.CheckExpiration != current.CheckExpiration) {
+      // CHECK_EXPIRATION requires CHECK_POLICY, so policy is turned on first and off last
+      if login.CheckPolicy {
+        options = append(options, "CHECK_POLICY = ON", "CHECK_EXPIRATION = "+onOff(login.CheckExpiration))
+      } else {
+        options = append(options, "CHECK_EXPIRATION = OFF", "CHECK_POLICY = OFF")
+      }
+    }
+  }
+  if len(options) == 0 {
+    return "", nil
+  }
+  return withOptions("ALTER LOGIN "+quoteName(login.LoginName, '['), options), nil
+}
+
+// Returns the PASSWORD option that sets the password or password hash of the login, or that unlocks the login if it is
+// locked and login.Unlock is set, or "" if there is nothing to set. UNLOCK is a clause of PASSWORD, so without a new
+// password the current hash is set again.
+func passwordOption(current, login *model.Login, server *serverInfo, mask bool) (string, error) {
+  unlock := login.Unlock && current.IsLocked
+  var password string
+  var err error
+  switch {
+  case login.Password != "" || login.PasswordHash != "":
+    if password, err = passwordValue(login, mask); err != nil {
+      return "", err
+    }
+    if login.MustChangePassword && !server.azure {
+      password += " MUST_CHANGE"
+    }
+  case unlock:
+    if current.PasswordHash == "" {
+      return "", errors.Errorf("login [%s] cannot be unlocked without a new password, as its password hash cannot be read", login.LoginName)
+    }
+    if password, err = passwordValue(&model.Login{PasswordHash: current.PasswordHash}, mask); err != nil {
+      return "", err
+    }
+  default:
+    return "", nil
+  }
+  if unlock {
+    password += " UNLOCK"
+  }
+  return "PASSWORD = " + password, nil
+}
+
+// Returns the value of the PASSWORD option for the password hash of the login, or else its password, masked if mask is
+// set
+func passwordValue(login *model.Login, mask bool) (string, error) {
+  if login.PasswordHash == "" {
+    if mask {
+      return maskedPassword, nil
+    }
+    return quoteName(login.Password, '\''), nil
+  }
+  hash, err := binaryLiteral("password_hash", login.PasswordHash)
+  if err != nil {
+    return "", err
+  }
+  if mask {
+    hash = maskedPasswordHash
+  }
+  return hash + " HASHED", nil
+}
+
+// Returns the statements that enable or disable the login, deny or grant it CONNECT SQL, and change its server roles and
+// credentials, where they differ from current. The server roles and credentials are left as they are when nil.
+func loginStateStatements(current, login *model.Login, server *serverInfo) []string {
+  var statements []string
+  name := quoteName(login.LoginName, '[')
+  if login.Enabled != current.Enabled {
+    if login.Enabled {
+      statements = append(statements, "ALTER LOGIN "+name+" ENABLE")
+    } else {
+      statements = append(statements, "ALTER LOGIN "+name+" DISABLE")
+    }
+  }
+  if login.DenyConnectSql != current.DenyConnectSql {
+    if login.DenyConnectSql {
+      statements = append(statements, "DENY CONNECT SQL TO "+name)
+    } else {
+      // GRANT replaces the DENY, whereas REVOKE would leave the login without CONNECT SQL
+      statements = append(statements, "GRANT CONNECT SQL TO "+name)
+    }
+  }
+  if login.ServerRoles != nil {
+    statements = append(statements, roleMemberStatements("ALTER SERVER ROLE", login.LoginName, current.ServerRoles, login.ServerRoles)...)
+  }
+  // Azure SQL Database has no server credentials
+  if login.Credentials != nil && !server.azure {
+    for _, credential := range sorted(difference(current.Credentials, login.Credentials)) {
+      statements = append(statements, "ALTER LOGIN "+name+" DROP CREDENTIAL "+quoteName(credential, '['))
+    }
+    for _, credential := range sorted(difference(login.Credentials, current.Credentials)) {
+      statements = append(statements, "ALTER LOGIN "+name+" ADD CREDENTIAL "+quoteName(credential, '['))
+    }
+  }
+  return statements
+}
+
+func renameLoginStatement(name, newName string) string {
+  return "ALTER LOGIN " + quoteName(name, '[') + " WITH NAME = " + quoteName(newName, '[')
+}
+
+func onOff(value bool) string {
+  if value {
+    return "ON"
+  }
+  return "OFF"
+}
+
+// Returns the T-SQL DeleteLogin runs for the login
+func DeleteLoginStatements(name, deleteSessions string, force bool) []string {
+  var statements []string
+  if !force {
+    statements = append(statements, "-- Fail if login "+quoteName(name, '\'')+" owns databases, endpoints or agent jobs")
+  }
+  switch deleteSessions {
+  case "wait":
+    statements = append(statements, "-- Wait for every session of login "+quoteName(name, '\'')+" to end")
+  case "fail":
+    statements = append(statements, "-- Fail if login "+quoteName(name, '\'')+" has sessions")
+  default:
+    statements = append(statements, "-- KILL every session of login "+quoteName(name, '\''))
+  }
+  if force {
+    statements = append(statements, "-- Transfer the ownership of databases, endpoints and agent jobs of login "+quoteName(name, '\'')+" to the sa login, or fail if the server has none")
+  }
+  return append(statements, "DROP LOGIN "+quoteName(name, '['))
+}
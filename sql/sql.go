@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net/url"
@@ -21,11 +22,35 @@ import (
 
 type factory struct{}
 
+// Implemented by both *schema.ResourceData and *schema.ResourceDiff
+type resourceGetter interface {
+  Get(key string) interface{}
+  GetOk(key string) (interface{}, bool)
+}
+
+// Connectors at plan time only look up names on the server, so an unreachable server must not hold up the plan. They
+// make a single attempt to connect, which gives up after planTimeout.
+const planTimeout = 5 * time.Second
+
+// Returned by connectors at plan time when no connection to the server can be opened, in which case the lookups on the
+// server are skipped
+var ErrServerUnreachable = errors.New("server not reachable")
+
 func GetFactory() model.ConnectorFactory {
   return new(factory)
 }
 
 func (f factory) GetConnector(prefix string, data *schema.ResourceData) (interface{}, error) {
+  return newConnector(prefix, data, data.Timeout(schema.TimeoutRead)), nil
+}
+
+func (f factory) GetDiffConnector(prefix string, diff *schema.ResourceDiff) (interface{}, error) {
+  connector := newConnector(prefix, diff, planTimeout)
+  connector.singleAttempt = true
+  return connector, nil
+}
+
+func newConnector(prefix string, data resourceGetter, timeout time.Duration) *Connector {
   if len(prefix) > 0 {
     prefix = prefix + ".0."
   }
@@ -33,7 +58,7 @@ func (f factory) GetConnector(prefix string, data *schema.ResourceData) (interfa
   connector := &Connector{
     Host:    data.Get(prefix + "host").(string),
     Port:    data.Get(prefix + "port").(string),
-    Timeout: data.Timeout(schema.TimeoutRead),
+    Timeout: timeout,
   }
 
   if admin, ok := data.GetOk(prefix + "login.0"); ok {
@@ -60,7 +85,7 @@ func (f factory) GetConnector(prefix string, data *schema.ResourceData) (interfa
     }
   }
 
-  return connector, nil
+  return connector
 }
 
 type Connector struct {
@@ -72,6 +97,8 @@ type Connector struct {
   FedauthMSI *FedauthMSI
   Timeout    time.Duration `json:"timeout,omitempty"`
   Token      string
+
+  singleAttempt bool
 }
 
 type LoginUser struct {
@@ -119,6 +146,23 @@ func (c *Connector) ExecContext(ctx context.Context, command string, args ...int
   return nil
 }
 
+// Execute SQL statements one at a time, stopping at the first that fails
+func (c *Connector) execStatements(ctx context.Context, statements []string) error {
+  db, err := c.db()
+  if err != nil {
+    return err
+  }
+  defer db.Close()
+
+  for _, statement := range statements {
+    if _, err = db.ExecContext(ctx, statement); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
+
 func (c *Connector) QueryContext(ctx context.Context, query string, scanner func(*sql.Rows) error, args ...interface{}) error {
   db, err := c.db()
   if err != nil {
@@ -155,6 +199,25 @@ func (c *Connector) QueryRowContext(ctx context.Context, query string, scanner f
   return scanner(row)
 }
 
+// Returns the values of the first column of the rows of query
+func (c *Connector) queryNames(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+  var names []string
+  err := c.QueryContext(ctx, query,
+    func(r *sql.Rows) error {
+      for r.Next() {
+        var name string
+        if err := r.Scan(&name); err != nil {
+          return err
+        }
+        names = append(names, name)
+      }
+      return r.Err()
+    },
+    args...,
+  )
+  return names, err
+}
+
 func (c *Connector) db() (*sql.DB, error) {
   if c == nil {
     panic("No connector")
@@ -163,6 +226,13 @@ func (c *Connector) db() (*sql.DB, error) {
   if err != nil {
     return nil, err
   }
+  if c.singleAttempt {
+    db, err := connectOnce(context.Background(), conn, c.Timeout)
+    if err != nil {
+      return nil, fmt.Errorf("%w: %v", ErrServerUnreachable, err)
+    }
+    return db, nil
+  }
   if db, err := connectLoop(conn, c.Timeout); err != nil {
     return nil, err
   } else {
@@ -273,6 +343,18 @@ func connectLoop(connector driver.Connector, timeout time.Duration) (*sql.DB, er
   }
 }
 
+// Opens a connection without retrying, giving up after timeout or when ctx is done
+func connectOnce(ctx context.Context, connector driver.Connector, timeout time.Duration) (*sql.DB, error) {
+  ctx, cancel := context.WithTimeout(ctx, timeout)
+  defer cancel()
+  db := sql.OpenDB(connector)
+  if err := db.PingContext(ctx); err != nil {
+    db.Close()
+    return nil, err
+  }
+  return db, nil
+}
+
 func connect(connector driver.Connector) (*sql.DB, error) {
   db := sql.OpenDB(connector)
   if err := db.Ping(); err != nil {
@@ -281,3 +363,57 @@ func connect(connector driver.Connector) (*sql.DB, error) {
   }
   return db, nil
 }
+
+// Facts about the server that decide the T-SQL generated for it
+type serverInfo struct {
+  azure           bool
+  defaultLanguage string
+}
+
+// Returns the facts about the server that the generated T-SQL depends on
+func (c *Connector) getServerInfo(ctx context.Context) (*serverInfo, error) {
+  cmd := `SELECT CAST(IIF(@@VERSION LIKE 'Microsoft SQL Azure%', 1, 0) AS bit),
+                 COALESCE((SELECT lang.name FROM [sys].[configurations] c INNER JOIN [sys].[syslanguages] lang ON c.[value] = lang.langid WHERE c.name = 'default language'), '')`
+  var server serverInfo
+  err := c.QueryRowContext(ctx, cmd,
+    func(r *sql.Row) error {
+      return r.Scan(&server.azure, &server.defaultLanguage)
+    },
+  )
+  if err != nil {
+    return nil, err
+  }
+  return &server, nil
+}
+
+// Masked password rendered in previews of the generated T-SQL
+const maskedPassword = "'********'"
+
+// Masked password hash rendered in previews of the generated T-SQL
+const maskedPasswordHash = "0x********"
+
+// Go equivalent of the T-SQL QUOTENAME function for the [ and ' delimiters
+func quoteName(name string, quote rune) string {
+  if quote == '\'' {
+    return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+  }
+  return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// Returns the hexadecimal value of key as a binary literal, so that only a well-formed value ends up in a statement
+func binaryLiteral(key, value string) (string, error) {
+  if len(value) > 2 && strings.EqualFold(value[:2], "0x") {
+    if _, err := hex.DecodeString(value[2:]); err == nil {
+      return value, nil
+    }
+  }
+  return "", errors.Errorf("%s must be a hexadecimal string starting with 0x", key)
+}
+
+// Appends the options to the statement as a WITH clause
+func withOptions(stmt string, options []string) string {
+  if len(options) == 0 {
+    return stmt
+  }
+  return stmt + " WITH " + strings.Join(options, ", ")
+}
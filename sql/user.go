@@ -3,57 +3,82 @@ package sql
 import (
   "context"
   "database/sql"
+  "encoding/hex"
   "github.com/betr-io/terraform-provider-mssql/mssql/model"
+  mssql "github.com/microsoft/go-mssqldb"
+  "github.com/pkg/errors"
+  "sort"
   "strings"
 )
 
 func (c *Connector) GetUser(ctx context.Context, database, username string) (*model.User, error) {
+  return c.getUser(ctx, database, username, 0)
+}
+
+// Looks up a user by its principal id, which unlike its name is kept when the user is renamed
+func (c *Connector) GetUserByPrincipalID(ctx context.Context, database string, principalID int64) (*model.User, error) {
+  return c.getUser(ctx, database, "", principalID)
+}
+
+// Looks up a user by its principal id if not 0, or else by its name
+func (c *Connector) getUser(ctx context.Context, database, username string, principalID int64) (*model.User, error) {
   cmd := `DECLARE @stmt nvarchar(max)
+          DECLARE @principal nvarchar(max) = IIF(@principalId = 0, 'DATABASE_PRINCIPAL_ID(' + QuoteName(@username, '''') + ')', CAST(@principalId AS nvarchar(max)))
           IF @@VERSION LIKE 'Microsoft SQL Azure%'
             BEGIN
-              SET @stmt = 'WITH CTE_Roles (principal_id, role_principal_id) AS ' +
+              SET @stmt = 'WITH CTE_Roles (principal_id, role_principal_id, direct) AS ' +
                           '(' +
-                          '  SELECT member_principal_id, role_principal_id FROM [sys].[database_role_members] WHERE member_principal_id = DATABASE_PRINCIPAL_ID(' + QuoteName(@username, '''') + ')' +
+                          '  SELECT member_principal_id, role_principal_id, 1 FROM [sys].[database_role_members] WHERE member_principal_id = ' + @principal +
                           '  UNION ALL ' +
-                          '  SELECT member_principal_id, drm.role_principal_id FROM [sys].[database_role_members] drm' +
+                          '  SELECT cr.principal_id, drm.role_principal_id, 0 FROM [sys].[database_role_members] drm' +
                           '    INNER JOIN CTE_Roles cr ON drm.member_principal_id = cr.role_principal_id' +
                           ') ' +
-                          'SELECT p.principal_id, p.name, p.authentication_type_desc, COALESCE(p.default_schema_name, ''''), COALESCE(p.default_language_name, ''''), p.sid, CONVERT(VARCHAR(1000), p.sid, 1) AS sidStr, '''', COALESCE(STRING_AGG(USER_NAME(r.role_principal_id), '',''), '''') ' +
+                          'SELECT p.principal_id, p.name, ' +
+                          '  CASE WHEN p.type = ''C'' THEN ''CERTIFICATE'' WHEN p.type = ''K'' THEN ''ASYMMETRIC_KEY'' WHEN p.authentication_type_desc = ''NONE'' THEN ''WITHOUT_LOGIN'' ELSE p.authentication_type_desc END, ' +
+                          '  CASE p.type WHEN ''X'' THEN ''group'' WHEN ''G'' THEN ''group'' WHEN ''E'' THEN ''user'' WHEN ''U'' THEN ''user'' ELSE '''' END, COALESCE(p.default_schema_name, ''''), COALESCE(p.default_language_name, ''''), p.sid, CONVERT(VARCHAR(1000), p.sid, 1) AS sidStr, '''', COALESCE(cert.name, ''''), COALESCE(ak.name, ''''), COALESCE(STRING_AGG(IIF(r.direct = 1, USER_NAME(r.role_principal_id), NULL), '',''), ''''), COALESCE(STRING_AGG(USER_NAME(r.role_principal_id), '',''), '''') ' +
                           'FROM [sys].[database_principals] p' +
                           '  LEFT JOIN CTE_Roles r ON p.principal_id = r.principal_id ' +
-                          'WHERE p.name = ' + QuoteName(@username, '''') + ' ' +
-                          'GROUP BY p.principal_id, p.name, p.authentication_type_desc, p.default_schema_name, p.default_language_name, p.sid'
+                          '  LEFT JOIN [sys].[certificates] cert ON p.sid = cert.sid ' +
+                          '  LEFT JOIN [sys].[asymmetric_keys] ak ON p.sid = ak.sid ' +
+                          'WHERE p.principal_id = ' + @principal + ' ' +
+                          'GROUP BY p.principal_id, p.name, p.authentication_type_desc, p.type, p.default_schema_name, p.default_language_name, p.sid, cert.name, ak.name'
             END
           ELSE
             BEGIN
-              SET @stmt = 'WITH CTE_Roles (principal_id, role_principal_id) AS ' +
+              SET @stmt = 'WITH CTE_Roles (principal_id, role_principal_id, direct) AS ' +
                           '(' +
-                          '  SELECT member_principal_id, role_principal_id FROM ' + QuoteName(@database) + '.[sys].[database_role_members] WHERE member_principal_id = DATABASE_PRINCIPAL_ID(' + QuoteName(@username, '''') + ')' +
+                          '  SELECT member_principal_id, role_principal_id, 1 FROM ' + QuoteName(@database) + '.[sys].[database_role_members] WHERE member_principal_id = ' + @principal +
                           '  UNION ALL ' +
-                          '  SELECT member_principal_id, drm.role_principal_id FROM ' + QuoteName(@database) + '.[sys].[database_role_members] drm' +
+                          '  SELECT cr.principal_id, drm.role_principal_id, 0 FROM ' + QuoteName(@database) + '.[sys].[database_role_members] drm' +
                           '    INNER JOIN CTE_Roles cr ON drm.member_principal_id = cr.role_principal_id' +
                           ') ' +
-                          'SELECT p.principal_id, p.name, p.authentication_type_desc, COALESCE(p.default_schema_name, ''''), COALESCE(p.default_language_name, ''''), p.sid, CONVERT(VARCHAR(1000), p.sid, 1) AS sidStr, COALESCE(sl.name, ''''), COALESCE(STRING_AGG(USER_NAME(r.role_principal_id), '',''), '''') ' +
+                          'SELECT p.principal_id, p.name, ' +
+                          '  CASE WHEN p.type = ''C'' THEN ''CERTIFICATE'' WHEN p.type = ''K'' THEN ''ASYMMETRIC_KEY'' WHEN p.authentication_type_desc = ''NONE'' THEN ''WITHOUT_LOGIN'' ELSE p.authentication_type_desc END, ' +
+                          '  CASE p.type WHEN ''X'' THEN ''group'' WHEN ''G'' THEN ''group'' WHEN ''E'' THEN ''user'' WHEN ''U'' THEN ''user'' ELSE '''' END, COALESCE(p.default_schema_name, ''''), COALESCE(p.default_language_name, ''''), p.sid, CONVERT(VARCHAR(1000), p.sid, 1) AS sidStr, COALESCE(sl.name, ''''), COALESCE(cert.name, ''''), COALESCE(ak.name, ''''), COALESCE(STRING_AGG(IIF(r.direct = 1, USER_NAME(r.role_principal_id), NULL), '',''), ''''), COALESCE(STRING_AGG(USER_NAME(r.role_principal_id), '',''), '''') ' +
                           'FROM ' + QuoteName(@database) + '.[sys].[database_principals] p' +
                           '  LEFT JOIN CTE_Roles r ON p.principal_id = r.principal_id ' +
-                          '  LEFT JOIN [master].[sys].[sql_logins] sl ON p.sid = sl.sid ' +
-                          'WHERE p.name = ' + QuoteName(@username, '''') + ' ' +
-                          'GROUP BY p.principal_id, p.name, p.authentication_type_desc, p.default_schema_name, p.default_language_name, p.sid, sl.name'
+                          '  LEFT JOIN [master].[sys].[server_principals] sl ON p.sid = sl.sid AND sl.type IN (''S'', ''U'', ''G'') ' +
+                          '  LEFT JOIN ' + QuoteName(@database) + '.[sys].[certificates] cert ON p.sid = cert.sid ' +
+                          '  LEFT JOIN ' + QuoteName(@database) + '.[sys].[asymmetric_keys] ak ON p.sid = ak.sid ' +
+                          'WHERE p.principal_id = ' + @principal + ' ' +
+                          'GROUP BY p.principal_id, p.name, p.authentication_type_desc, p.type, p.default_schema_name, p.default_language_name, p.sid, sl.name, cert.name, ak.name'
             END
           EXEC (@stmt)`
   var (
-    user  model.User
-    sid   []byte
-    roles string
+    user           model.User
+    sid            []byte
+    roles          string
+    effectiveRoles string
   )
   err := c.
     setDatabase(&database).
     QueryRowContext(ctx, cmd,
       func(r *sql.Row) error {
-        return r.Scan(&user.PrincipalID, &user.Username, &user.AuthType, &user.DefaultSchema, &user.DefaultLanguage, &sid, &user.SIDStr, &user.LoginName, &roles)
+        return r.Scan(&user.PrincipalID, &user.Username, &user.AuthType, &user.PrincipalType, &user.DefaultSchema, &user.DefaultLanguage, &sid, &user.SIDStr, &user.LoginName, &user.CertificateName, &user.AsymmetricKeyName, &roles, &effectiveRoles)
       },
       sql.Named("database", database),
       sql.Named("username", username),
+      sql.Named("principalId", principalID),
     )
   if err != nil {
     if err == sql.ErrNoRows {
@@ -70,201 +95,178 @@ func (c *Connector) GetUser(ctx context.Context, database, username string) (*mo
       },
       sql.Named("sid", sid),
     )
-    if err != nil {
+    // A user whose SID matches no login, e.g. after the database was restored onto another server, is orphaned
+    if err != nil && err != sql.ErrNoRows {
       return nil, err
     }
   }
+  user.Roles = splitRoles(roles)
+  user.EffectiveRoles = splitRoles(effectiveRoles)
+  return &user, nil
+}
+
+// Splits a comma-separated list of roles. A role inherited through several nested roles is listed once.
+func splitRoles(roles string) []string {
+  result := make([]string, 0)
   if roles == "" {
-    user.Roles = make([]string, 0)
-  } else {
-    user.Roles = strings.Split(roles, ",")
+    return result
   }
-  return &user, nil
+  seen := make(map[string]bool)
+  for _, role := range strings.Split(roles, ",") {
+    if !seen[role] {
+      seen[role] = true
+      result = append(result, role)
+    }
+  }
+  return result
 }
 
-func (c *Connector) CreateUser(ctx context.Context, database string, user *model.User) error {
-  cmd := `DECLARE @stmt nvarchar(max)
-          DECLARE @language nvarchar(max) = @defaultLanguage
-          IF @language = '' SET @language = NULL
-          IF @authType = 'INSTANCE'
-            BEGIN
-              SET @stmt = 'CREATE USER ' + QuoteName(@username) + ' FOR LOGIN ' + QuoteName(@loginName) + ' ' +
-                          'WITH DEFAULT_SCHEMA = ' + QuoteName(@defaultSchema)
-            END
-          IF @authType = 'DATABASE'
-            BEGIN
-              SET @stmt = 'CREATE USER ' + QuoteName(@username) + ' WITH PASSWORD = ' + QuoteName(@password, '''') + ', ' +
-                          'DEFAULT_SCHEMA = ' + QuoteName(@defaultSchema)
-              IF NOT @@VERSION LIKE 'Microsoft SQL Azure%'
-                BEGIN
-                  SET @stmt = @stmt + ', DEFAULT_LANGUAGE = ' + Coalesce(QuoteName(@language), 'NONE')
-                END
-            END
-          IF @authType = 'EXTERNAL'
-            BEGIN
-              IF @@VERSION LIKE 'Microsoft SQL Azure%'
-                BEGIN
-                  IF @objectId != ''
-                    BEGIN
-                      SET @stmt = 'CREATE USER ' + QuoteName(@username) + ' WITH SID=' + CONVERT(varchar(64), CAST(CAST(@objectId AS UNIQUEIDENTIFIER) AS VARBINARY(16)), 1) + ', TYPE=E'
-                    END
-                  ELSE
-                    BEGIN
-                      SET @stmt = 'CREATE USER ' + QuoteName(@username) + ' FROM EXTERNAL PROVIDER'
-                  END
-                END
-              ELSE
-                BEGIN
-                  SET @stmt = 'CREATE USER ' + QuoteName(@username) + ' FOR LOGIN ' + QuoteName(@username) + ' FROM EXTERNAL PROVIDER ' +
-                              'WITH DEFAULT_SCHEMA = ' + QuoteName(@defaultSchema) + ', ' +
-                              'DEFAULT_LANGUAGE = ' + Coalesce(QuoteName(@language), 'NONE')
-                END
-            END
+// Reports whether password is the current password of the contained database user. The password hashes of contained
+// users cannot be read, so this logs in to the database as the user. A mismatch is a failed login (error 18456) that
+// the server audits and counts towards any lockout policy, so the login is attempted only once, giving up after
+// planTimeout.
+func (c *Connector) UserPasswordMatches(ctx context.Context, database, username, password string) (bool, error) {
+  connector := &Connector{
+    Host:     c.Host,
+    Port:     c.Port,
+    Database: database,
+    Login:    &LoginUser{Username: username, Password: password},
+  }
+  conn, err := connector.connector()
+  if err != nil {
+    return false, err
+  }
+  db, err := connectOnce(ctx, conn, planTimeout)
+  if err != nil {
+    var sqlErr mssql.Error
+    // Login failed for user
+    if errors.As(err, &sqlErr) && sqlErr.SQLErrorNumber() == 18456 {
+      return false, nil
+    }
+    return false, err
+  }
+  return true, db.Close()
+}
 
-          BEGIN TRANSACTION;
-          EXEC sp_getapplock @Resource = 'create_func', @LockMode = 'Exclusive';
-          IF exists (select compatibility_level FROM sys.databases where name = db_name() and compatibility_level < 130) AND objectproperty(object_id('String_Split'), 'isProcedure') IS NULL
-          BEGIN
-              DECLARE @sql NVARCHAR(MAX);
-              SET @sql = N'Create FUNCTION [dbo].[String_Split]
-                    (
-                        @string    nvarchar(max),
-                        @delimiter nvarchar(max)
-                    )
-                    /*
-                        The same as STRING_SPLIT for compatibility level < 130
-                        https://docs.microsoft.com/en-us/sql/t-sql/functions/string-split-transact-sql?view=sql-server-ver15
-                    */
-                    RETURNS TABLE AS RETURN
-                    (
-                        SELECT
-                          --ROW_NUMBER ( ) over(order by (select 0))                            AS id     --  intuitive, but not correect
-                            Split.a.value(''let $n := . return count(../*[. << $n]) + 1'', ''int'') AS id
-                          , Split.a.value(''.'', ''NVARCHAR(MAX)'')                                 AS value
-                        FROM
-                        (
-                            SELECT CAST(''<X>''+REPLACE(@string, @delimiter, ''</X><X>'')+''</X>'' AS XML) AS String
-                        ) AS a
-                        CROSS APPLY String.nodes(''/X'') AS Split(a)
-                    )';
-              EXEC sp_executesql @sql;
-          END
-          EXEC sp_releaseapplock @Resource = 'create_func';
-          COMMIT TRANSACTION;
-          SET @stmt = @stmt + '; ' +
-                      'DECLARE role_cur CURSOR FOR SELECT name FROM ' + QuoteName(@database) + '.[sys].[database_principals] WHERE type = ''R'' AND name != ''public'' AND name COLLATE SQL_Latin1_General_CP1_CI_AS IN (SELECT value FROM String_Split(' + QuoteName(@roles, '''') + ', '',''));' +
-                      'DECLARE @role nvarchar(max);' +
-                      'OPEN role_cur;' +
-                      'FETCH NEXT FROM role_cur INTO @role;' +
-                      'WHILE @@FETCH_STATUS = 0' +
-                      '  BEGIN' +
-                      '    DECLARE @sql nvarchar(max);' +
-                      '    SET @sql = ''ALTER ROLE '' + QuoteName(@role) + '' ADD MEMBER ' + QuoteName(@username) + ''';' +
-                      '    EXEC (@sql);' +
-                      '    FETCH NEXT FROM role_cur INTO @role;' +
-                      '  END;' +
-                      'CLOSE role_cur;' +
-                      'DEALLOCATE role_cur;'
-          EXEC (@stmt)`
-  if user.AuthType != "EXTERNAL" {
-    // External users do not have a server login
+// Returns the names of the database roles in database
+func (c *Connector) GetRoleNames(ctx context.Context, database string) ([]string, error) {
+  return c.
+    setDatabase(&database).
+    queryNames(ctx, "SELECT name FROM [sys].[database_principals] WHERE type = 'R'")
+}
+
+// Returns the names of the schemas in database
+func (c *Connector) GetSchemaNames(ctx context.Context, database string) ([]string, error) {
+  return c.
+    setDatabase(&database).
+    queryNames(ctx, "SELECT name FROM [sys].[schemas]")
+}
+
+func (c *Connector) CreateUser(ctx context.Context, database string, user *model.User) error {
+  if user.AuthType == "INSTANCE" || (user.AuthType == "WINDOWS" && user.LoginName != "") {
+    // Only users that authenticate at the server have a server login
     _, err := c.GetLogin(ctx, user.LoginName)
     if err != nil {
       return err
     }
   }
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return err
+  }
+  statements, err := createUserStatements(user, server, quoteName(user.Password, '\''))
+  if err != nil {
+    return err
+  }
   return c.
     setDatabase(&database).
-    ExecContext(ctx, cmd,
-      sql.Named("database", database),
-      sql.Named("username", user.Username),
-      sql.Named("objectId", user.ObjectId),
-      sql.Named("loginName", user.LoginName),
-      sql.Named("password", user.Password),
-      sql.Named("authType", user.AuthType),
-      sql.Named("defaultSchema", user.DefaultSchema),
-      sql.Named("defaultLanguage", user.DefaultLanguage),
-      sql.Named("roles", strings.Join(user.Roles, ",")),
-    )
+    execStatements(ctx, statements)
+}
+
+// Returns the statements that create the user on the server and add it to its roles, with password as the password
+// literal. The CREATE USER is the first statement, and each statement is run on its own, as a failed statement does not
+// end a batch and the roles must never be granted to a user that already exists.
+func createUserStatements(user *model.User, server *serverInfo, password string) ([]string, error) {
+  create, err := createUserStatement(user, server, password)
+  if err != nil {
+    return nil, err
+  }
+  return append([]string{create}, roleMemberStatements("ALTER ROLE", user.Username, nil, user.Roles)...), nil
 }
 
+// Returns the CREATE USER statement for the user on the server, with password as the password literal
+func createUserStatement(user *model.User, server *serverInfo, password string) (string, error) {
+  stmt := "CREATE USER " + quoteName(user.Username, '[')
+  schema := "DEFAULT_SCHEMA = " + quoteName(user.DefaultSchema, '[')
+  switch user.AuthType {
+  case "INSTANCE":
+    return stmt + " FOR LOGIN " + quoteName(user.LoginName, '[') + " WITH " + schema, nil
+  case "DATABASE":
+    options := []string{"PASSWORD = " + password, schema}
+    if !server.azure {
+      options = append(options, "DEFAULT_LANGUAGE = "+languageOrNone(user.DefaultLanguage))
+    }
+    return withOptions(stmt, options), nil
+  case "WINDOWS":
+    // A Windows user without a login is a contained Windows user, or else is mapped to the login of the same name
+    if user.LoginName != "" {
+      stmt += " FOR LOGIN " + quoteName(user.LoginName, '[')
+    }
+    return stmt + " WITH " + schema, nil
+  case "WITHOUT_LOGIN":
+    return stmt + " WITHOUT LOGIN WITH " + schema, nil
+  // Users mapped to a certificate or an asymmetric key have no default schema
+  case "CERTIFICATE":
+    return stmt + " FOR CERTIFICATE " + quoteName(user.CertificateName, '['), nil
+  case "ASYMMETRIC_KEY":
+    return stmt + " FOR ASYMMETRIC KEY " + quoteName(user.AsymmetricKeyName, '['), nil
+  case "EXTERNAL":
+    if !server.azure {
+      return stmt + " FOR LOGIN " + quoteName(user.Username, '[') + " FROM EXTERNAL PROVIDER WITH " + schema + ", DEFAULT_LANGUAGE = " + languageOrNone(user.DefaultLanguage), nil
+    }
+    if user.ObjectId == "" {
+      return stmt + " FROM EXTERNAL PROVIDER", nil
+    }
+    sid, err := objectIdToSid(user.ObjectId)
+    if err != nil {
+      return "", err
+    }
+    return stmt + " WITH SID=" + sid + ", TYPE=" + externalUserType(user.PrincipalType), nil
+  }
+  return "", errors.Errorf("unknown authentication type %s", user.AuthType)
+}
+
+// Brings the user in database to user. Only the options and roles that differ from the user as read from the database
+// are changed, with the same statements UpdateUserStatements previews.
 func (c *Connector) UpdateUser(ctx context.Context, database string, user *model.User) error {
-  cmd := `DECLARE @stmt nvarchar(max)
-          SET @stmt = 'ALTER USER ' + QuoteName(@username) + ' '
-          DECLARE @language nvarchar(max) = @defaultLanguage
-          IF @language = '' SET @language = NULL
-          SET @stmt = @stmt + 'WITH DEFAULT_SCHEMA = ' + QuoteName(@defaultSchema)
-          DECLARE @auth_type nvarchar(max) = (SELECT authentication_type_desc FROM [sys].[database_principals] WHERE name = @username)
-          IF NOT @@VERSION LIKE 'Microsoft SQL Azure%' AND @auth_type != 'INSTANCE'
-            BEGIN
-              SET @stmt = @stmt + ', DEFAULT_LANGUAGE = ' + Coalesce(QuoteName(@language), 'NONE')
-            END
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return err
+  }
+  current, err := c.GetUser(ctx, database, user.Username)
+  if err != nil {
+    return err
+  }
+  if current == nil {
+    return errors.Errorf("user [%s].[%s] not found", database, user.Username)
+  }
+  statements := updateUserStatements(current, user, server, quoteName(user.Password, '\''), quoteName(user.OldPassword, '\''))
+  return c.
+    setDatabase(&database).
+    execStatements(ctx, statements)
+}
 
-          BEGIN TRANSACTION;
-          EXEC sp_getapplock @Resource = 'create_func', @LockMode = 'Exclusive';
-          IF exists (select compatibility_level FROM sys.databases where name = db_name() and compatibility_level < 130) AND objectproperty(object_id('String_Split'), 'isProcedure') IS NULL
-          BEGIN
-              DECLARE @sql NVARCHAR(MAX);
-              SET @sql = N'Create FUNCTION [dbo].[String_Split]
-                    (
-                        @string    nvarchar(max),
-                        @delimiter nvarchar(max)
-                    )
-                    /*
-                        The same as STRING_SPLIT for compatibility level < 130
-                        https://docs.microsoft.com/en-us/sql/t-sql/functions/string-split-transact-sql?view=sql-server-ver15
-                    */
-                    RETURNS TABLE AS RETURN
-                    (
-                        SELECT
-                          --ROW_NUMBER ( ) over(order by (select 0))                            AS id     --  intuitive, but not correect
-                            Split.a.value(''let $n := . return count(../*[. << $n]) + 1'', ''int'') AS id
-                          , Split.a.value(''.'', ''NVARCHAR(MAX)'')                                 AS value
-                        FROM
-                        (
-                            SELECT CAST(''<X>''+REPLACE(@string, @delimiter, ''</X><X>'')+''</X>'' AS XML) AS String
-                        ) AS a
-                        CROSS APPLY String.nodes(''/X'') AS Split(a)
-                    )';
-              EXEC sp_executesql @sql;
-          END
-          EXEC sp_releaseapplock @Resource = 'create_func';
-          COMMIT TRANSACTION;
-          SET @stmt = @stmt + '; ' +
-                      'DECLARE @sql nvarchar(max);' +
-                      'DECLARE @role nvarchar(max);' +
-                      'DECLARE del_role_cur CURSOR FOR SELECT name FROM ' + QuoteName(@database) + '.[sys].[database_principals] WHERE type = ''R'' AND name != ''public'' AND name IN (SELECT name FROM ' + QuoteName(@database) + '.[sys].[database_role_members] drm, ' + QuoteName(@database) + '.[sys].[database_principals] db WHERE drm.member_principal_id = DATABASE_PRINCIPAL_ID(' + QuoteName(@username, '''') + ') AND drm.role_principal_id = db.principal_id) AND name COLLATE SQL_Latin1_General_CP1_CI_AS NOT IN(SELECT value FROM String_Split(' + QuoteName(@roles, '''') + ', '',''));' +
-                      'DECLARE add_role_cur CURSOR FOR SELECT name FROM ' + QuoteName(@database) + '.[sys].[database_principals] WHERE type = ''R'' AND name != ''public'' AND name NOT IN (SELECT name FROM ' + QuoteName(@database) + '.[sys].[database_role_members] drm, ' + QuoteName(@database) + '.[sys].[database_principals] db WHERE drm.member_principal_id = DATABASE_PRINCIPAL_ID(' + QuoteName(@username, '''') + ') AND drm.role_principal_id = db.principal_id) AND name COLLATE SQL_Latin1_General_CP1_CI_AS IN(SELECT value FROM String_Split(' + QuoteName(@roles, '''') + ', '',''));' +
-                      'OPEN del_role_cur;' +
-                      'FETCH NEXT FROM del_role_cur INTO @role;' +
-                      'WHILE @@FETCH_STATUS = 0' +
-                      '  BEGIN' +
-                      '    SET @sql = ''ALTER ROLE '' + QuoteName(@role) + '' DROP MEMBER ' + QuoteName(@username) + ''';' +
-                      '    EXEC (@sql);' +
-                      '    FETCH NEXT FROM del_role_cur INTO @role;' +
-                      '  END;' +
-                      'CLOSE del_role_cur;' +
-                      'DEALLOCATE del_role_cur;' +
-                      'OPEN add_role_cur;' +
-                      'FETCH NEXT FROM add_role_cur INTO @role;' +
-                      'WHILE @@FETCH_STATUS = 0' +
-                      '  BEGIN' +
-                      '    SET @sql = ''ALTER ROLE '' + QuoteName(@role) + '' ADD MEMBER ' + QuoteName(@username) + ''';' +
-                      '    EXEC (@sql);' +
-                      '    FETCH NEXT FROM add_role_cur INTO @role;' +
-                      '  END;' +
-                      'CLOSE add_role_cur;' +
-                      'DEALLOCATE add_role_cur;'
-          EXEC (@stmt)`
+func (c *Connector) RenameUser(ctx context.Context, database, username, newUsername string) error {
   return c.
     setDatabase(&database).
-    ExecContext(ctx, cmd,
-      sql.Named("database", database),
-      sql.Named("username", user.Username),
-      sql.Named("defaultSchema", user.DefaultSchema),
-      sql.Named("defaultLanguage", user.DefaultLanguage),
-      sql.Named("roles", strings.Join(user.Roles, ",")),
-    )
+    ExecContext(ctx, renameUserStatement(username, newUsername))
+}
+
+// Maps the user to the login, which also repairs a user orphaned by a login that no longer exists
+func (c *Connector) RemapUser(ctx context.Context, database, username, loginName string) error {
+  return c.
+    setDatabase(&database).
+    ExecContext(ctx, remapUserStatement(username, loginName))
 }
 
 func (c *Connector) DeleteUser(ctx context.Context, database, username string) error {
@@ -277,6 +279,149 @@ func (c *Connector) DeleteUser(ctx context.Context, database, username string) e
     ExecContext(ctx, cmd, sql.Named("database", database), sql.Named("username", username))
 }
 
+// Returns the T-SQL CreateUser runs for the user, with the password masked
+func (c *Connector) CreateUserStatements(ctx context.Context, database string, user *model.User) ([]string, error) {
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return createUserStatements(user, server, maskedPassword)
+}
+
+// Returns the T-SQL RenameUser, RemapUser and UpdateUser run to bring the user from old to new, with the password masked.
+// The statements are built against the user as read from the database, or against old if it is not found.
+func (c *Connector) UpdateUserStatements(ctx context.Context, database string, old, new *model.User) ([]string, error) {
+  server, err := c.setDatabase(&database).getServerInfo(ctx)
+  if err != nil {
+    return nil, err
+  }
+  current, err := c.GetUser(ctx, database, old.Username)
+  if err != nil {
+    return nil, err
+  }
+  if current == nil {
+    current = old
+  }
+  statements := make([]string, 0)
+  if new.Username != old.Username {
+    statements = append(statements, renameUserStatement(old.Username, new.Username))
+  }
+  if new.LoginName != old.LoginName {
+    statements = append(statements, remapUserStatement(new.Username, new.LoginName))
+  }
+  renamed := *current
+  renamed.Username = new.Username
+  return append(statements, updateUserStatements(&renamed, new, server, maskedPassword, maskedPassword)...), nil
+}
+
+// Returns the statements that bring current, the user as read from the database, to user, with password and oldPassword
+// as the literals of its password and old password. The password is only set when user has one.
+func updateUserStatements(current, user *model.User, server *serverInfo, password, oldPassword string) []string {
+  var statements []string
+  var options []string
+  // Users mapped to a certificate or an asymmetric key have no default schema
+  if current.AuthType != "CERTIFICATE" && current.AuthType != "ASYMMETRIC_KEY" && !strings.EqualFold(user.DefaultSchema, current.DefaultSchema) {
+    options = append(options, "DEFAULT_SCHEMA = "+quoteName(user.DefaultSchema, '['))
+  }
+  if user.Password != "" {
+    option := "PASSWORD = " + password
+    // The old password lets a user without ALTER ANY USER permission change its own password
+    if user.OldPassword != "" {
+      option += " OLD_PASSWORD = " + oldPassword
+    }
+    options = append(options, option)
+  }
+  if !server.azure && (current.AuthType == "DATABASE" || current.AuthType == "EXTERNAL") && !strings.EqualFold(user.DefaultLanguage, current.DefaultLanguage) {
+    options = append(options, "DEFAULT_LANGUAGE = "+languageOrNone(user.DefaultLanguage))
+  }
+  if len(options) > 0 {
+    statements = append(statements, withOptions("ALTER USER "+quoteName(user.Username, '['), options))
+  }
+  return append(statements, roleMemberStatements("ALTER ROLE", user.Username, current.Roles, user.Roles)...)
+}
+
+func renameUserStatement(username, newUsername string) string {
+  return "ALTER USER " + quoteName(username, '[') + " WITH NAME = " + quoteName(newUsername, '[')
+}
+
+func remapUserStatement(username, loginName string) string {
+  return "ALTER USER " + quoteName(username, '[') + " WITH LOGIN = " + quoteName(loginName, '[')
+}
+
+// Returns the T-SQL DeleteUser runs for the user
+func DeleteUserStatements(username string) []string {
+  return []string{"DROP USER " + quoteName(username, '[')}
+}
+
+// Returns the TYPE of an external user created with a SID, which is X for Microsoft Entra groups and E for users and
+// applications
+func externalUserType(principalType string) string {
+  if principalType == "group" {
+    return "X"
+  }
+  return "E"
+}
+
+func languageOrNone(language string) string {
+  if language == "" {
+    return "NONE"
+  }
+  return quoteName(language, '[')
+}
+
+// Converts an object id to the SID of the external user, as CONVERT(varchar(64), CAST(CAST(@objectId AS
+// UNIQUEIDENTIFIER) AS VARBINARY(16)), 1) does
+func objectIdToSid(objectId string) (string, error) {
+  b, err := hex.DecodeString(strings.ReplaceAll(objectId, "-", ""))
+  if err != nil || len(b) != 16 {
+    return "", errors.Errorf("object_id [%s] is not a UUID", objectId)
+  }
+  // The first three groups of a UNIQUEIDENTIFIER are stored little-endian
+  b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
+  b[4], b[5] = b[5], b[4]
+  b[6], b[7] = b[7], b[6]
+  return "0x" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// Returns the statements that make member a member of exactly roles, given that it is a member of current, with
+// alterRole as ALTER ROLE or ALTER SERVER ROLE. Every principal is a member of public, which takes no members.
+func roleMemberStatements(alterRole, member string, current, roles []string) []string {
+  var statements []string
+  for _, role := range sorted(difference(current, roles)) {
+    statements = append(statements, alterRole+" "+quoteName(role, '[')+" DROP MEMBER "+quoteName(member, '['))
+  }
+  for _, role := range sorted(difference(roles, current)) {
+    if !strings.EqualFold(role, "public") {
+      statements = append(statements, alterRole+" "+quoteName(role, '[')+" ADD MEMBER "+quoteName(member, '['))
+    }
+  }
+  return statements
+}
+
+func sorted(values []string) []string {
+  result := append([]string(nil), values...)
+  sort.Strings(result)
+  return result
+}
+
+// Values in a that are not in b, ignoring case as the server does
+func difference(a, b []string) []string {
+  var result []string
+  for _, va := range a {
+    found := false
+    for _, vb := range b {
+      if strings.EqualFold(va, vb) {
+        found = true
+        break
+      }
+    }
+    if !found {
+      result = append(result, va)
+    }
+  }
+  return result
+}
+
 func (c *Connector) setDatabase(database *string) *Connector {
   if *database == "" {
     *database = "master"